@@ -0,0 +1,262 @@
+// Package analyzer reports misuse of the binding API that would otherwise only
+// show up as a panic when the handlers are created or served: pointer models
+// passed to the binders, and HandlerFunc functions whose results HandlerFunc
+// can not write. It reports them with the same messages binding panics with.
+//
+// cmd/bindingvet runs it with go vet:
+//
+//	go install go.wandrs.dev/binding/analyzer/cmd/bindingvet@latest
+//	go vet -vettool=$(which bindingvet) ./...
+//
+// It is a module of its own so that binding does not depend on x/tools.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const bindingPath = "go.wandrs.dev/binding"
+
+// Analyzer reports misuse of the binding API.
+var Analyzer = &analysis.Analyzer{
+	Name:     "binding",
+	Doc:      "report binding models and HandlerFunc functions that make binding panic",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// binders are the functions that panic when given a pointer model.
+var binders = map[string]bool{
+	"Bind":          true,
+	"Form":          true,
+	"MultipartForm": true,
+	"JSON":          true,
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != bindingPath || len(call.Args) == 0 {
+			return
+		}
+		switch {
+		case binders[fn.Name()]:
+			checkModel(pass, call.Args[0])
+		case fn.Name() == "HandlerFunc":
+			checkHandler(pass, call.Args[0])
+		}
+	})
+	return nil, nil
+}
+
+// checkModel reports pointer models, see ensureNotPointer.
+func checkModel(pass *analysis.Pass, model ast.Expr) {
+	if _, ok := pass.TypesInfo.TypeOf(model).Underlying().(*types.Pointer); ok {
+		pass.Reportf(model.Pos(), "Pointers are not accepted as binding models")
+	}
+}
+
+// checkHandler reports the functions newHandlerPlan rejects, and functions
+// returning nothing that can not write to the ResponseWriter.
+func checkHandler(pass *analysis.Pass, handler ast.Expr) {
+	typ := pass.TypesInfo.TypeOf(handler)
+	if typ == nil || isUntypedNil(typ) {
+		return
+	}
+	name := typeString(typ)
+	sig, ok := typ.Underlying().(*types.Signature)
+	if !ok {
+		pass.Reportf(handler.Pos(), "fn %s must be a function, found %s", name, kindOf(typ))
+		return
+	}
+
+	results := sig.Results()
+	switch results.Len() {
+	case 0:
+		if !writes(pass, handler, sig) {
+			pass.Reportf(handler.Pos(), "fn %s must write to ResponseWriter, since it returns nothing", name)
+		}
+	case 1:
+		if etyp := results.At(0).Type(); !types.Implements(etyp, errorType) && types.Implements(types.NewPointer(etyp), errorType) {
+			pass.Reportf(handler.Pos(), "fn %s return type should be *%s to be considered an error", name, typeName(etyp))
+		}
+	case 2:
+		if etyp := results.At(1).Type(); !types.Implements(etyp, errorType) {
+			if types.Implements(types.NewPointer(etyp), errorType) {
+				pass.Reportf(handler.Pos(), "fn %s 2nd return value should be *%s to be considered an error", name, typeName(etyp))
+			} else {
+				pass.Reportf(handler.Pos(), "2nd return value must implement error")
+			}
+			return
+		}
+		if types.Implements(results.At(0).Type(), errorType) {
+			pass.Reportf(handler.Pos(), "fn %s 1st return value must not an error", name)
+		}
+	default:
+		pass.Reportf(handler.Pos(), "fn %s has %d return values, at most 2 are allowed", name, results.Len())
+	}
+}
+
+// writes reports whether the handler may write the response: it has to take a
+// parameter it can write with, and use it if its body is known.
+func writes(pass *analysis.Pass, handler ast.Expr, sig *types.Signature) bool {
+	writer := false
+	for i := 0; i < sig.Params().Len(); i++ {
+		writer = writer || canWrite(sig.Params().At(i).Type())
+	}
+	if !writer {
+		return false
+	}
+
+	var body *ast.BlockStmt
+	var params *ast.FieldList
+	switch h := ast.Unparen(handler).(type) {
+	case *ast.FuncLit:
+		body, params = h.Body, h.Type.Params
+	case *ast.Ident:
+		if decl := funcDecl(pass, pass.TypesInfo.Uses[h]); decl != nil {
+			body, params = decl.Body, decl.Type.Params
+		}
+	}
+	if body == nil {
+		return true // declared elsewhere, give it the benefit of the doubt
+	}
+
+	used := map[types.Object]bool{}
+	for _, field := range params.List {
+		for _, id := range field.Names {
+			if obj := pass.TypesInfo.Defs[id]; obj != nil && canWrite(obj.Type()) {
+				used[obj] = false
+			}
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if _, ok := used[pass.TypesInfo.Uses[id]]; ok {
+				used[pass.TypesInfo.Uses[id]] = true
+			}
+		}
+		return true
+	})
+	for _, ok := range used {
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// funcDecl returns the declaration of the function obj of the package under
+// analysis.
+func funcDecl(pass *analysis.Pass, obj types.Object) *ast.FuncDecl {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() != pass.Pkg {
+		return nil
+	}
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil && pass.TypesInfo.Defs[decl.Name] == fn {
+				return decl
+			}
+		}
+	}
+	return nil
+}
+
+// canWrite reports whether a handler can write the response with a value of
+// typ: it is an io.Writer, like http.ResponseWriter, or an inject.Injector to
+// look one up.
+func canWrite(typ types.Type) bool {
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == "go.wandrs.dev/inject" && named.Obj().Name() == "Injector" {
+		return true
+	}
+	write, _, _ := types.LookupFieldOrMethod(typ, true, nil, "Write")
+	fn, ok := write.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 1 && types.Identical(sig.Params().At(0).Type(), types.NewSlice(types.Typ[types.Byte]))
+}
+
+// typeString is typ like reflect.Type.String prints it, with package names
+// rather than paths and without the names of function parameters.
+func typeString(typ types.Type) string {
+	return types.TypeString(withoutParamNames(typ), func(p *types.Package) string { return p.Name() })
+}
+
+// withoutParamNames returns typ with the parameters and results of the
+// function types it is made of unnamed.
+func withoutParamNames(typ types.Type) types.Type {
+	switch t := typ.(type) {
+	case *types.Signature:
+		unnamed := func(vars *types.Tuple) *types.Tuple {
+			list := make([]*types.Var, vars.Len())
+			for i := range list {
+				v := vars.At(i)
+				list[i] = types.NewParam(v.Pos(), v.Pkg(), "", withoutParamNames(v.Type()))
+			}
+			return types.NewTuple(list...)
+		}
+		return types.NewSignatureType(nil, nil, nil, unnamed(t.Params()), unnamed(t.Results()), t.Variadic())
+	case *types.Pointer:
+		return types.NewPointer(withoutParamNames(t.Elem()))
+	case *types.Slice:
+		return types.NewSlice(withoutParamNames(t.Elem()))
+	case *types.Array:
+		return types.NewArray(withoutParamNames(t.Elem()), t.Len())
+	case *types.Map:
+		return types.NewMap(withoutParamNames(t.Key()), withoutParamNames(t.Elem()))
+	case *types.Chan:
+		return types.NewChan(t.Dir(), withoutParamNames(t.Elem()))
+	}
+	return typ
+}
+
+// typeName is the name of typ like reflect.Type.Name reports it.
+func typeName(typ types.Type) string {
+	if named, ok := typ.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// kindOf is the reflect.Kind of typ.
+func kindOf(typ types.Type) string {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return t.Name()
+	case *types.Pointer:
+		return "ptr"
+	case *types.Array:
+		return "array"
+	case *types.Slice:
+		return "slice"
+	case *types.Map:
+		return "map"
+	case *types.Chan:
+		return "chan"
+	case *types.Struct:
+		return "struct"
+	case *types.Interface:
+		return "interface"
+	}
+	return fmt.Sprintf("%T", typ)
+}
+
+func isUntypedNil(typ types.Type) bool {
+	basic, ok := typ.(*types.Basic)
+	return ok && basic.Kind() == types.UntypedNil
+}
@@ -0,0 +1,13 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"go.wandrs.dev/binding/analyzer"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "a")
+}
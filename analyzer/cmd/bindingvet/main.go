@@ -0,0 +1,15 @@
+// Command bindingvet reports misuse of the binding API, see package analyzer.
+// It runs standalone or as a go vet tool:
+//
+//	go vet -vettool=$(which bindingvet) ./...
+package main
+
+import (
+	"go.wandrs.dev/binding/analyzer"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,47 @@
+package a
+
+import (
+	"net/http"
+
+	"go.wandrs.dev/binding"
+)
+
+type Post struct {
+	Title string
+}
+
+type postError struct{}
+
+func (*postError) Error() string { return "post" }
+
+func models() {
+	binding.Bind(Post{})
+	binding.Bind(&Post{})          // want "Pointers are not accepted as binding models"
+	binding.Form(new(Post))        // want "Pointers are not accepted as binding models"
+	binding.MultipartForm(&Post{}) // want "Pointers are not accepted as binding models"
+	binding.JSON(&Post{})          // want "Pointers are not accepted as binding models"
+}
+
+func getPost(w http.ResponseWriter) {
+	w.Write(nil)
+}
+
+func ignorePost(w http.ResponseWriter) {}
+
+func handlers() {
+	binding.HandlerFunc(func(p Post) (Post, error) { return p, nil })
+	binding.HandlerFunc(func(p Post) error { return nil })
+	binding.HandlerFunc(func(p Post) *postError { return nil })
+	binding.HandlerFunc(getPost)
+	binding.HandlerFunc(http.NotFound)
+
+	binding.HandlerFunc(Post{})                                                    // want `fn a.Post must be a function, found struct`
+	binding.HandlerFunc(func() postError { return postError{} })                   // want `fn func\(\) a.postError return type should be \*postError to be considered an error`
+	binding.HandlerFunc(func() (Post, postError) { return Post{}, postError{} })   // want `fn func\(\) \(a.Post, a.postError\) 2nd return value should be \*postError to be considered an error`
+	binding.HandlerFunc(func() (Post, Post) { return Post{}, Post{} })             // want `2nd return value must implement error`
+	binding.HandlerFunc(func() (error, error) { return nil, nil })                 // want `fn func\(\) \(error, error\) 1st return value must not an error`
+	binding.HandlerFunc(func() (Post, Post, error) { return Post{}, Post{}, nil }) // want `fn func\(\) \(a.Post, a.Post, error\) has 3 return values, at most 2 are allowed`
+	binding.HandlerFunc(func(p Post) {})                                           // want `fn func\(a.Post\) must write to ResponseWriter, since it returns nothing`
+	binding.HandlerFunc(func(p Post, next func(p Post) bool, ids ...int) {})       // want `fn func\(a.Post, func\(a.Post\) bool, \.\.\.int\) must write to ResponseWriter, since it returns nothing`
+	binding.HandlerFunc(ignorePost)                                                // want `fn func\(http.ResponseWriter\) must write to ResponseWriter, since it returns nothing`
+}
@@ -0,0 +1,12 @@
+// Package binding stubs the API of go.wandrs.dev/binding the analyzer checks.
+package binding
+
+import "net/http"
+
+func Bind(obj interface{}, ifacePtr ...interface{}) func(http.Handler) http.Handler { return nil }
+func Form(obj interface{}, ifacePtr ...interface{}) func(http.Handler) http.Handler { return nil }
+func MultipartForm(obj interface{}, ifacePtr ...interface{}) func(http.Handler) http.Handler {
+	return nil
+}
+func JSON(obj interface{}, ifacePtr ...interface{}) func(http.Handler) http.Handler { return nil }
+func HandlerFunc(fn interface{}) http.HandlerFunc                                   { return nil }
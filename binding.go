@@ -18,6 +18,7 @@
 package binding
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 	"reflect"
@@ -43,10 +44,11 @@ var json = jsoniter.ConfigCompatibleWithStandardLibrary
 // occurred. If you want to perform your own error handling, use
 // Form or Json middleware directly. An interface pointer can
 // be added as a second argument in order to map the struct to
-// a specific interface.
+// a specific interface. Options like JSONSchema can be added too.
 func Bind(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+	plan := newBindingPlan(binderBind, obj, ifacePtr...)
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return plan.handler(func(w http.ResponseWriter, r *http.Request) {
 			injector, _ := r.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
@@ -57,11 +59,11 @@ func Bind(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http
 			if r.Method == http.MethodPost || r.Method == http.MethodPut || len(contentType) > 0 {
 				switch {
 				case strings.Contains(contentType, "form-urlencoded"):
-					err = bindForm(r, injector, obj, ifacePtr...)
+					err = bindForm(r, injector, plan)
 				case strings.Contains(contentType, "multipart/form-data"):
-					err = bindMultipartForm(r, injector, obj, ifacePtr...)
+					err = bindMultipartForm(r, injector, plan)
 				case strings.Contains(contentType, "json"):
-					err = bindJSON(r, injector, obj, ifacePtr...)
+					err = bindJSON(r, injector, plan)
 				default:
 					status := metav1.Status{
 						Status: metav1.StatusFailure,
@@ -76,12 +78,11 @@ func Bind(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http
 					err = &apierrors.StatusError{status}
 				}
 			} else {
-				err = bindForm(r, injector, obj, ifacePtr...)
+				err = bindForm(r, injector, plan)
 			}
 
 			if err != nil {
-				ww := ResponseWriter(injector)
-				ww.APIError(err)
+				writeError(injector, err)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -96,18 +97,20 @@ func Bind(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http
 // into the struct with the proper type. Structs with primitive slice types
 // (bool, float, int, string) can support deserialization of repeated form
 // keys, for example: key=val1&key=val2&key=val3
+// Code generated by cmd/bindinggen and registered with RegisterGenerated
+// replaces the reflection for the model, here and in the other binders.
 // An interface pointer can be added as a second argument in order
 // to map the struct to a specific interface.
 func Form(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+	plan := newBindingPlan(binderForm, obj, ifacePtr...)
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return plan.handler(func(w http.ResponseWriter, r *http.Request) {
 			injector, _ := r.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
 			}
-			if err := bindForm(r, injector, obj, ifacePtr...); err != nil {
-				ww := ResponseWriter(injector)
-				ww.APIError(err)
+			if err := bindForm(r, injector, plan); err != nil {
+				writeError(injector, err)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -115,27 +118,22 @@ func Form(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http
 	}
 }
 
-func bindForm(r *http.Request, injector inject.Injector, obj interface{}, ifacePtr ...interface{}) *apierrors.StatusError {
-	ensureNotPointer(obj)
-	newObj := reflect.New(reflect.TypeOf(obj))
+func bindForm(r *http.Request, injector inject.Injector, plan *bindingPlan) *apierrors.StatusError {
+	newObj := reflect.New(plan.typ)
 
 	if err := r.ParseForm(); err != nil {
 		return apierrors.NewBadRequest(err.Error())
 	}
 
-	d := form.NewDecoder()
-	if err := d.Decode(newObj.Interface(), r.Form); err != nil {
-		return NewBindingError(err, obj)
+	if err := plan.decodeForm(newObj, r.Form); err != nil {
+		return NewBindingError(err, newObj.Elem().Interface())
 	}
 
-	if err := check(newObj); err != nil {
-		return NewBindingError(err, obj)
+	if err := plan.validate(newObj); err != nil {
+		return NewBindingError(err, newObj.Elem().Interface())
 	}
 
-	injector.Map(newObj.Elem().Interface())
-	if len(ifacePtr) > 0 {
-		injector.MapTo(newObj.Elem().Interface(), ifacePtr[0])
-	}
+	plan.inject(injector, newObj.Elem())
 	return nil
 }
 
@@ -148,15 +146,15 @@ var MaxMemory = int64(1024 * 1024 * 10)
 // you can pass in an interface to make the interface available for injection
 // into other handlers later.
 func MultipartForm(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+	plan := newBindingPlan(binderMultipartForm, obj, ifacePtr...)
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return plan.handler(func(w http.ResponseWriter, r *http.Request) {
 			injector, _ := r.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
 			}
-			if err := bindMultipartForm(r, injector, obj, ifacePtr...); err != nil {
-				ww := ResponseWriter(injector)
-				ww.APIError(err)
+			if err := bindMultipartForm(r, injector, plan); err != nil {
+				writeError(injector, err)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -164,10 +162,8 @@ func MultipartForm(obj interface{}, ifacePtr ...interface{}) func(next http.Hand
 	}
 }
 
-func bindMultipartForm(r *http.Request, injector inject.Injector, obj interface{}, ifacePtr ...interface{}) *apierrors.StatusError {
-	ensureNotPointer(obj)
-
-	newObj := reflect.New(reflect.TypeOf(obj))
+func bindMultipartForm(r *http.Request, injector inject.Injector, plan *bindingPlan) *apierrors.StatusError {
+	newObj := reflect.New(plan.typ)
 	// This if check is necessary due to https://github.com/martini-contrib/csrf/issues/6
 	if r.Form == nil {
 		if err := r.ParseMultipartForm(MaxMemory); err != nil {
@@ -175,19 +171,15 @@ func bindMultipartForm(r *http.Request, injector inject.Injector, obj interface{
 		}
 	}
 
-	d := form.NewDecoder()
-	if err := d.Decode(newObj.Interface(), r.Form); err != nil {
-		return NewBindingError(err, obj)
+	if err := plan.decodeForm(newObj, r.Form); err != nil {
+		return NewBindingError(err, newObj.Elem().Interface())
 	}
 
-	if err := check(newObj); err != nil {
-		return NewBindingError(err, obj)
+	if err := plan.validate(newObj); err != nil {
+		return NewBindingError(err, newObj.Elem().Interface())
 	}
 
-	injector.Map(newObj.Elem().Interface())
-	if len(ifacePtr) > 0 {
-		injector.MapTo(newObj.Elem().Interface(), ifacePtr[0])
-	}
+	plan.inject(injector, newObj.Elem())
 	return nil
 }
 
@@ -195,7 +187,8 @@ func bindMultipartForm(r *http.Request, injector inject.Injector, obj interface{
 // into the struct that is passed in. The resulting struct is then
 // validated, but no error handling is actually performed here.
 // An interface pointer can be added as a second argument in order
-// to map the struct to a specific interface.
+// to map the struct to a specific interface. Options like JSONSchema
+// can be added too.
 //
 // For all requests, Json parses the raw query from the URL using matching struct json tags.
 //
@@ -205,15 +198,15 @@ func bindMultipartForm(r *http.Request, injector inject.Injector, obj interface{
 // Json follows the Request.ParseForm() method from Go's net/http library.
 // ref: https://github.com/golang/go/blob/700e969d5b23732179ea86cfe67e8d1a0a1cc10a/src/net/http/request.go#L1176
 func JSON(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+	plan := newBindingPlan(binderJSON, obj, ifacePtr...)
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return plan.handler(func(w http.ResponseWriter, r *http.Request) {
 			injector, _ := r.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
 			}
-			if err := bindJSON(r, injector, obj, ifacePtr...); err != nil {
-				ww := ResponseWriter(injector)
-				ww.APIError(err)
+			if err := bindJSON(r, injector, plan); err != nil {
+				writeError(injector, err)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -221,38 +214,122 @@ func JSON(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http
 	}
 }
 
-func bindJSON(r *http.Request, injector inject.Injector, obj interface{}, ifacePtr ...interface{}) *apierrors.StatusError {
-	ensureNotPointer(obj)
-	newObj := reflect.New(reflect.TypeOf(obj))
+func bindJSON(r *http.Request, injector inject.Injector, plan *bindingPlan) *apierrors.StatusError {
+	newObj := reflect.New(plan.typ)
 
 	if r.URL != nil {
 		if params := r.URL.Query(); len(params) > 0 {
-			d := form.NewDecoder()
-			d.SetTagName("json")
-			if err := d.Decode(newObj.Interface(), params); err != nil {
-				return NewBindingError(err, obj)
+			if err := plan.decodeQuery(newObj, params); err != nil {
+				return NewBindingError(err, newObj.Elem().Interface())
 			}
 		}
 	}
 	if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch {
+		var body *limitedJSON
 		if r.Body != nil {
-			if err := json.NewDecoder(r.Body).Decode(newObj.Interface()); err != nil && err != io.EOF {
+			body = plan.limits.jsonReader(r.Body)
+		}
+		if body != nil && plan.schema != nil {
+			data, err := io.ReadAll(body)
+			if body.err != nil {
+				return body.err
+			} else if err != nil {
+				return apierrors.NewBadRequest(err.Error())
+			}
+			if len(bytes.TrimSpace(data)) > 0 {
+				if err := validateSchema(plan.schema, data); err != nil {
+					return NewBindingError(err, newObj.Elem().Interface())
+				}
+				if err := plan.decodeJSON(newObj, bytes.NewReader(data)); err != nil {
+					return apierrors.NewBadRequest(err.Error())
+				}
+			}
+		} else if body != nil {
+			if err := plan.decodeJSON(newObj, body); body.err != nil {
+				return body.err
+			} else if err != nil && err != io.EOF {
 				return apierrors.NewBadRequest(err.Error())
 			}
 		}
 	}
 
-	if err := check(newObj); err != nil {
-		return NewBindingError(err, obj)
+	if err := plan.validate(newObj); err != nil {
+		return NewBindingError(err, newObj.Elem().Interface())
 	}
 
-	injector.Map(newObj.Elem().Interface())
-	if len(ifacePtr) > 0 {
-		injector.MapTo(newObj.Elem().Interface(), ifacePtr[0])
-	}
+	plan.inject(injector, newObj.Elem())
 	return nil
 }
 
+// bindingPlan holds everything about a binding model that does not change
+// between requests. It is built once when a binding middleware is created so
+// that the form decoders keep their struct cache warm across requests.
+type bindingPlan struct {
+	binder    binderKind
+	typ       reflect.Type
+	ifaceType reflect.Type
+
+	form  *form.Decoder // decodes form values using the form tags
+	query *form.Decoder // decodes query parameters using the json tags
+
+	schema *jsonSchema // validates JSON bodies, see JSONSchema
+	limits Limits      // bound the input, see Limit
+}
+
+// binderKind tells which middleware a bindingPlan belongs to.
+type binderKind int
+
+const (
+	binderBind binderKind = iota
+	binderForm
+	binderMultipartForm
+	binderJSON
+)
+
+func newBindingPlan(binder binderKind, obj interface{}, ifacePtr ...interface{}) *bindingPlan {
+	ensureNotPointer(obj)
+
+	plan := &bindingPlan{
+		binder: binder,
+		typ:    reflect.TypeOf(obj),
+		form:   form.NewDecoder(),
+		query:  form.NewDecoder(),
+	}
+	plan.query.SetTagName("json")
+	plan.setLimits(DefaultLimits)
+	for _, arg := range ifacePtr {
+		if opt, ok := arg.(Option); ok {
+			opt(plan)
+		} else if plan.ifaceType == nil {
+			plan.ifaceType = inject.InterfaceOf(arg)
+		}
+	}
+	return plan
+}
+
+// types returns the types the bound value is injected as.
+func (plan *bindingPlan) types() []reflect.Type {
+	if plan.ifaceType != nil {
+		return []reflect.Type{plan.typ, plan.ifaceType}
+	}
+	return []reflect.Type{plan.typ}
+}
+
+// handler returns the http.Handler of the binding middleware, which reports the
+// plan to Verify and OpenAPI.
+func (plan *bindingPlan) handler(h http.HandlerFunc) http.Handler {
+	return &injectingHandler{HandlerFunc: h, types: plan.types(), model: plan}
+}
+
+// inject maps the bound value under the model type and, if one was given,
+// under the interface type.
+func (plan *bindingPlan) inject(injector inject.Injector, val reflect.Value) {
+	injector.Set(plan.typ, val)
+	if plan.ifaceType != nil {
+		injector.Set(plan.ifaceType, val)
+	}
+}
+
 // Don't pass in pointers to bind to. Can lead to bugs.
 func ensureNotPointer(obj interface{}) {
 	if reflect.TypeOf(obj).Kind() == reflect.Ptr {
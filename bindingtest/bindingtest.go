@@ -0,0 +1,129 @@
+// Package bindingtest provides utilities for testing models and handlers that
+// use binding: builders for JSON, form and multipart requests, a harness that
+// runs a model through a binder and returns the bound value, and assertions on
+// the metav1.Status of error responses.
+//
+//	post, resp := bindingtest.Run[Post](t, binding.JSON, bindingtest.Post("/").JSON(`{"content": "Lorem ipsum"}`))
+//	bindingtest.AssertCause(t, resp, "title", metav1.CauseTypeFieldValueRequired)
+package bindingtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Binder is one of binding.Bind, binding.Form, binding.MultipartForm and
+// binding.JSON.
+type Binder func(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler
+
+// NewRouter returns a router that maps an injector rendering with
+// render.New(), like applications do with binding.Injector.
+func NewRouter() chi.Router {
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	return m
+}
+
+// Serve serves req with h and returns the recorded response.
+func Serve(h http.Handler, req *Request) *http.Response {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req.HTTP())
+	return w.Result()
+}
+
+// Run binds a T from req with binder and returns it along with the response,
+// which is 200 OK when the model was bound and validated and the error Status
+// otherwise. The returned T is the zero value if binding failed.
+func Run[T any](t testing.TB, binder Binder, req *Request) (T, *http.Response) {
+	t.Helper()
+	var obj T
+	var bound T
+	m := NewRouter()
+	m.With(binder(obj)).Handle("/*", binding.HandlerFunc(func(w http.ResponseWriter, v T) {
+		bound = v
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	resp := Serve(m, req)
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		t.Fatalf("bindingtest: %s %s was not routed", req.method, req.target)
+	}
+	return bound, resp
+}
+
+// DecodeStatus decodes the metav1.Status in the body of resp. The body can be
+// read again afterwards.
+func DecodeStatus(t testing.TB, resp *http.Response) metav1.Status {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var status metav1.Status
+	require.NoError(t, json.Unmarshal(body, &status), "body is not a metav1.Status: %s", body)
+	return status
+}
+
+// AssertCode asserts that resp has the status code code.
+func AssertCode(t testing.TB, resp *http.Response, code int) bool {
+	t.Helper()
+	if resp.StatusCode == code {
+		return true
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return assert.Equal(t, code, resp.StatusCode, "body: %s", body)
+}
+
+// AssertReason asserts that resp is a metav1.Status with reason.
+func AssertReason(t testing.TB, resp *http.Response, reason metav1.StatusReason) bool {
+	t.Helper()
+	status := DecodeStatus(t, resp)
+	return assert.Equal(t, reason, status.Reason, "message: %s", status.Message)
+}
+
+// AssertCause asserts that resp is a metav1.Status with a cause of type typ
+// for field. The field is compared regardless of case with the field of the
+// causes as is, with the namespace validator reports but the leading model
+// name, and with a JSON Schema instance location but the leading slash, so
+// "author.name" matches "BlogPost.Author.Name" and "/author/name" as well.
+func AssertCause(t testing.TB, resp *http.Response, field string, typ metav1.CauseType) bool {
+	t.Helper()
+	status := DecodeStatus(t, resp)
+	if status.Details == nil {
+		return assert.Fail(t, "Status has no details", "message: %s", status.Message)
+	}
+	for _, cause := range status.Details.Causes {
+		if cause.Type == typ && matchField(cause.Field, field) {
+			return true
+		}
+	}
+	return assert.Fail(t, "Status has no "+string(typ)+" cause for "+field, "causes: %+v", status.Details.Causes)
+}
+
+func matchField(got, want string) bool {
+	if strings.EqualFold(got, want) {
+		return true
+	}
+	if _, ns, ok := strings.Cut(got, "."); ok && strings.EqualFold(ns, want) {
+		return true
+	}
+	if strings.HasPrefix(got, "/") {
+		return strings.EqualFold(strings.ReplaceAll(got[1:], "/", "."), want)
+	}
+	return false
+}
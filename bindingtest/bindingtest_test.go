@@ -0,0 +1,75 @@
+package bindingtest_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/bindingtest"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type (
+	Post struct {
+		Title   string  `json:"title" form:"title" validate:"required"`
+		Ratings []int   `json:"ratings" form:"rating"`
+		Author  *Person `json:"author" form:"author"`
+	}
+
+	Person struct {
+		Name string `json:"name" form:"name" validate:"required"`
+	}
+)
+
+func TestRunJSON(t *testing.T) {
+	post, resp := bindingtest.Run[Post](t, binding.JSON, bindingtest.Post("/posts").JSON(Post{Title: "Glorious Post Title", Ratings: []int{3, 5}}))
+	bindingtest.AssertCode(t, resp, http.StatusOK)
+	assert.Equal(t, Post{Title: "Glorious Post Title", Ratings: []int{3, 5}}, post)
+
+	post, resp = bindingtest.Run[Post](t, binding.JSON, bindingtest.Post("/posts").JSON(`{"author": {}}`))
+	bindingtest.AssertCode(t, resp, http.StatusUnprocessableEntity)
+	bindingtest.AssertReason(t, resp, metav1.StatusReasonInvalid)
+	bindingtest.AssertCause(t, resp, "title", metav1.CauseTypeFieldValueRequired)
+	bindingtest.AssertCause(t, resp, "author.name", metav1.CauseTypeFieldValueRequired)
+	assert.Zero(t, post)
+
+	_, resp = bindingtest.Run[Post](t, binding.JSON, bindingtest.Post("/posts").JSON(`{"title":`))
+	bindingtest.AssertCode(t, resp, http.StatusBadRequest)
+}
+
+func TestRunForm(t *testing.T) {
+	req := bindingtest.Post("/").Form("title", "Glorious Post Title").Form("rating", "3", "5").Form("author.name", "Matt Holt")
+	post, resp := bindingtest.Run[Post](t, binding.Form, req)
+	bindingtest.AssertCode(t, resp, http.StatusOK)
+	assert.Equal(t, Post{Title: "Glorious Post Title", Ratings: []int{3, 5}, Author: &Person{Name: "Matt Holt"}}, post)
+
+	post, resp = bindingtest.Run[Post](t, binding.Bind, bindingtest.Get("/").Query("title", "Glorious Post Title"))
+	bindingtest.AssertCode(t, resp, http.StatusOK)
+	assert.Equal(t, "Glorious Post Title", post.Title)
+
+	_, resp = bindingtest.Run[Post](t, binding.Form, bindingtest.Post("/").Form("rating", "five"))
+	bindingtest.AssertCause(t, resp, "rating", metav1.CauseTypeFieldValueInvalid)
+}
+
+func TestServeMultipart(t *testing.T) {
+	var cover []byte
+	m := bindingtest.NewRouter()
+	m.With(binding.MultipartForm(Post{})).Post("/posts", binding.HandlerFunc(func(w http.ResponseWriter, r *http.Request, post Post) {
+		assert.Equal(t, "Glorious Post Title", post.Title)
+		f, _, err := r.FormFile("cover")
+		if assert.NoError(t, err) {
+			cover, _ = io.ReadAll(f)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := bindingtest.Post("/posts").Form("title", "Glorious Post Title").File("cover", "cover.png", []byte("png"))
+	bindingtest.AssertCode(t, bindingtest.Serve(m, req), http.StatusOK)
+	assert.Equal(t, "png", string(cover))
+
+	resp := bindingtest.Serve(m, bindingtest.Post("/posts").Multipart())
+	bindingtest.AssertCause(t, resp, "title", metav1.CauseTypeFieldValueRequired)
+}
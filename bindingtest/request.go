@@ -0,0 +1,199 @@
+package bindingtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+const (
+	jsonContentType = "application/json; charset=utf-8"
+	formContentType = "application/x-www-form-urlencoded"
+)
+
+// Request builds the requests served by Serve and Run. Its methods return the
+// Request, so that they can be chained:
+//
+//	req := bindingtest.Post("/posts").Form("title", "Glorious Post Title").File("cover", "cover.png", png)
+//
+// A Request with files is sent as multipart/form-data, one with form values as
+// application/x-www-form-urlencoded and one with a JSON body as
+// application/json, unless ContentType sets another content type.
+type Request struct {
+	method      string
+	target      string
+	header      http.Header
+	query       url.Values
+	form        url.Values
+	files       []file
+	multipart   bool
+	body        []byte
+	hasBody     bool
+	json        bool
+	contentType *string
+}
+
+type file struct {
+	field, name string
+	content     []byte
+}
+
+// NewRequest returns a Request for method and target, like httptest.NewRequest.
+func NewRequest(method, target string) *Request {
+	return &Request{
+		method: method,
+		target: target,
+		header: http.Header{},
+		query:  url.Values{},
+		form:   url.Values{},
+	}
+}
+
+// Get returns a GET Request for target.
+func Get(target string) *Request {
+	return NewRequest(http.MethodGet, target)
+}
+
+// Post returns a POST Request for target.
+func Post(target string) *Request {
+	return NewRequest(http.MethodPost, target)
+}
+
+// Put returns a PUT Request for target.
+func Put(target string) *Request {
+	return NewRequest(http.MethodPut, target)
+}
+
+// Patch returns a PATCH Request for target.
+func Patch(target string) *Request {
+	return NewRequest(http.MethodPatch, target)
+}
+
+// Header adds the header key with value.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+// ContentType sets the Content-Type header, replacing the one derived from the
+// body. An empty content type sends the request without one.
+func (r *Request) ContentType(contentType string) *Request {
+	r.contentType = &contentType
+	return r
+}
+
+// Query adds values to the query parameter key.
+func (r *Request) Query(key string, values ...string) *Request {
+	r.query[key] = append(r.query[key], values...)
+	return r
+}
+
+// Form adds values to the form field key.
+func (r *Request) Form(key string, values ...string) *Request {
+	r.form[key] = append(r.form[key], values...)
+	return r
+}
+
+// File adds a file named name with content to the form field key, and makes
+// the request a multipart one.
+func (r *Request) File(key, name string, content []byte) *Request {
+	r.files = append(r.files, file{field: key, name: name, content: content})
+	r.multipart = true
+	return r
+}
+
+// Multipart sends the form as multipart/form-data, even without files.
+func (r *Request) Multipart() *Request {
+	r.multipart = true
+	return r
+}
+
+// JSON sets the body to v encoded as JSON. A string or []byte is sent as is,
+// so that malformed JSON can be sent as well.
+func (r *Request) JSON(v interface{}) *Request {
+	switch v := v.(type) {
+	case string:
+		r.Body([]byte(v))
+	case []byte:
+		r.Body(v)
+	default:
+		body, err := json.Marshal(v)
+		if err != nil {
+			panic("bindingtest: " + err.Error())
+		}
+		r.Body(body)
+	}
+	r.json = true
+	return r
+}
+
+// Body sets the body to body as is.
+func (r *Request) Body(body []byte) *Request {
+	r.body = body
+	r.hasBody = true
+	r.json = false
+	return r
+}
+
+// HTTP returns the request as an *http.Request. Like httptest.NewRequest, it
+// panics if the request can not be built.
+func (r *Request) HTTP() *http.Request {
+	var body io.Reader
+	contentType := ""
+	switch {
+	case r.multipart:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for key, values := range r.form {
+			for _, v := range values {
+				must(w.WriteField(key, v))
+			}
+		}
+		for _, f := range r.files {
+			part, err := w.CreateFormFile(f.field, f.name)
+			must(err)
+			_, err = part.Write(f.content)
+			must(err)
+		}
+		must(w.Close())
+		body, contentType = &buf, w.FormDataContentType()
+	case len(r.form) > 0:
+		body, contentType = strings.NewReader(r.form.Encode()), formContentType
+	case r.hasBody:
+		body = bytes.NewReader(r.body)
+		if r.json {
+			contentType = jsonContentType
+		}
+	}
+	if r.contentType != nil {
+		contentType = *r.contentType
+	}
+
+	target := r.target
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	for key, values := range r.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+func must(err error) {
+	if err != nil {
+		panic("bindingtest: " + err.Error())
+	}
+}
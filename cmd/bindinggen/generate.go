@@ -0,0 +1,963 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// function names the generated functions of a struct.
+type function int
+
+const (
+	decodeForm function = iota
+	decodeQuery
+	decodeJSON
+	validate
+	numFunctions
+)
+
+var functionNames = [numFunctions]string{"decodeForm", "decodeQuery", "decodeJSON", "validate"}
+
+// typeKind classifies the field types bindinggen generates code for.
+type typeKind int
+
+const (
+	unsupportedKind typeKind = iota
+	scalarKind               // bool, string, numbers and named types of them
+	pointerKind              // pointer to a scalar or a struct
+	sliceKind                // slice of scalars or structs
+	structKind               // struct type declared in the package
+)
+
+type typeInfo struct {
+	kind  typeKind
+	expr  string // Go type expression, like Color or *Address
+	basic string // underlying basic type of a scalar
+	rtype string // reflect.Type string of a scalar, used in decode errors
+	elem  *typeInfo
+	strct *structInfo
+
+	// unmarshaler is set if the type implements json.Unmarshaler or
+	// encoding.TextUnmarshaler, which only the JSON decoder honours.
+	unmarshaler bool
+	// maybeStruct is set if an unsupported type may be a struct with validate
+	// tags of its own.
+	maybeStruct bool
+}
+
+type field struct {
+	name     string // Go field name
+	typ      *typeInfo
+	tag      reflect.StructTag
+	embedded bool
+}
+
+type structInfo struct {
+	name    string
+	spec    *ast.StructType
+	fields  []*field
+	support [numFunctions]int // 0 unknown, 1 supported, -1 unsupported, 2 in progress
+}
+
+type generator struct {
+	pkg     string
+	specs   map[string]*ast.TypeSpec
+	methods map[string]map[string]bool
+	structs map[string]*structInfo
+	imports map[string]bool
+	called  map[call]bool
+	pending []call
+	buf     bytes.Buffer
+}
+
+// call is a generated function of a struct.
+type call struct {
+	s  *structInfo
+	fn function
+}
+
+// generate returns the formatted source registering the generated binders of
+// types, which are declared in files of package pkg.
+func generate(pkg string, files []*ast.File, types []string, command string) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		specs:   map[string]*ast.TypeSpec{},
+		methods: map[string]map[string]bool{},
+		structs: map[string]*structInfo{},
+		imports: map[string]bool{},
+		called:  map[call]bool{},
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						g.specs[ts.Name.Name] = ts
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) != 1 {
+					continue
+				}
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if id, ok := recv.(*ast.Ident); ok {
+					if g.methods[id.Name] == nil {
+						g.methods[id.Name] = map[string]bool{}
+					}
+					g.methods[id.Name][decl.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	roots := make([]*structInfo, 0, len(types))
+	for _, name := range types {
+		name = strings.TrimSpace(name)
+		spec, ok := g.specs[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg)
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || spec.TypeParams != nil {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		s, err := g.structOf(name, st)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, s)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("func init() {\n")
+	for _, s := range roots {
+		fmt.Fprintf(&body, "binding.RegisterGenerated(binding.Generated[%s]{\n", s.name)
+		for fn, key := range []string{"DecodeForm", "DecodeQuery", "DecodeJSON", "Validate"} {
+			ok, err := g.supported(s, function(fn))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				fmt.Fprintf(&body, "%s: %s,\n", key, g.call(s, function(fn)))
+			}
+		}
+		body.WriteString("})\n")
+	}
+	body.WriteString("}\n")
+	g.imports["go.wandrs.dev/binding"] = true
+
+	for len(g.pending) > 0 {
+		c := g.pending[0]
+		g.pending = g.pending[1:]
+		body.WriteString("\n")
+		if err := g.emit(&body, c.s, c.fn); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by \"%s\"; DO NOT EDIT.\n\n", command)
+	fmt.Fprintf(&g.buf, "package %s\n\n", pkg)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	sort.SliceStable(imports, func(i, j int) bool {
+		return !strings.Contains(imports[i], ".") && strings.Contains(imports[j], ".")
+	})
+	g.buf.WriteString("import (\n")
+	for i, path := range imports {
+		if i > 0 && strings.Contains(path, ".") && !strings.Contains(imports[i-1], ".") {
+			g.buf.WriteString("\n")
+		}
+		switch path {
+		case "github.com/json-iterator/go":
+			fmt.Fprintf(&g.buf, "jsoniter %q\n", path)
+		default:
+			fmt.Fprintf(&g.buf, "%q\n", path)
+		}
+	}
+	g.buf.WriteString(")\n\n")
+	g.buf.Write(body.Bytes())
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("internal error: invalid Go generated: %s\n%s", err, g.buf.Bytes())
+	}
+	return src, nil
+}
+
+func funcName(s *structInfo, fn function) string {
+	return "_" + s.name + "_" + functionNames[fn]
+}
+
+// call returns the name of the function fn of s, queueing it to be emitted.
+func (g *generator) call(s *structInfo, fn function) string {
+	c := call{s, fn}
+	if !g.called[c] {
+		g.called[c] = true
+		g.pending = append(g.pending, c)
+	}
+	return funcName(s, fn)
+}
+
+// structOf returns the fields of the struct type name.
+func (g *generator) structOf(name string, st *ast.StructType) (*structInfo, error) {
+	if s, ok := g.structs[name]; ok {
+		return s, nil
+	}
+	s := &structInfo{name: name, spec: st}
+	g.structs[name] = s
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			v, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(v)
+		}
+		typ, err := g.resolve(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		if len(f.Names) == 0 {
+			expr := f.Type
+			if star, ok := expr.(*ast.StarExpr); ok {
+				expr = star.X
+			}
+			name := ""
+			switch expr := expr.(type) {
+			case *ast.Ident:
+				name = expr.Name
+			case *ast.SelectorExpr:
+				name = expr.Sel.Name
+			}
+			s.fields = append(s.fields, &field{name: name, typ: typ, tag: tag, embedded: true})
+			continue
+		}
+		for _, id := range f.Names {
+			if !id.IsExported() {
+				continue
+			}
+			s.fields = append(s.fields, &field{name: id.Name, typ: typ, tag: tag})
+		}
+	}
+	return s, nil
+}
+
+var basicTypes = map[string]string{
+	"bool": "bool", "string": "string",
+	"int": "int", "int8": "int8", "int16": "int16", "int32": "int32", "int64": "int64",
+	"uint": "uint", "uint8": "uint8", "uint16": "uint16", "uint32": "uint32", "uint64": "uint64",
+	"float32": "float32", "float64": "float64",
+	"byte": "uint8", "rune": "int32",
+}
+
+// resolve classifies the field type expr.
+func (g *generator) resolve(expr ast.Expr) (*typeInfo, error) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if basic, ok := basicTypes[expr.Name]; ok && g.specs[expr.Name] == nil {
+			return &typeInfo{kind: scalarKind, expr: expr.Name, basic: basic, rtype: basic}, nil
+		}
+		spec, ok := g.specs[expr.Name]
+		if !ok || spec.TypeParams != nil {
+			return &typeInfo{expr: expr.Name}, nil
+		}
+		unmarshaler := g.methods[expr.Name]["UnmarshalJSON"] || g.methods[expr.Name]["UnmarshalText"]
+		switch underlying := spec.Type.(type) {
+		case *ast.StructType:
+			s, err := g.structOf(expr.Name, underlying)
+			if err != nil {
+				return nil, err
+			}
+			return &typeInfo{kind: structKind, expr: expr.Name, strct: s, unmarshaler: unmarshaler}, nil
+		case *ast.Ident:
+			if spec.Assign.IsValid() {
+				return g.resolve(underlying)
+			}
+			if basic, ok := basicTypes[underlying.Name]; ok {
+				return &typeInfo{kind: scalarKind, expr: expr.Name, basic: basic, rtype: g.pkg + "." + expr.Name, unmarshaler: unmarshaler}, nil
+			}
+			if t, err := g.resolve(underlying); err != nil || t.kind != scalarKind {
+				return &typeInfo{expr: expr.Name, maybeStruct: t != nil && t.kind == structKind}, err
+			} else {
+				return &typeInfo{kind: scalarKind, expr: expr.Name, basic: t.basic, rtype: g.pkg + "." + expr.Name, unmarshaler: unmarshaler}, nil
+			}
+		}
+		return &typeInfo{expr: expr.Name}, nil
+	case *ast.StarExpr:
+		elem, err := g.resolve(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		t := &typeInfo{expr: "*" + elem.expr, elem: elem, maybeStruct: elem.maybeStruct}
+		if elem.kind == scalarKind || elem.kind == structKind {
+			t.kind = pointerKind
+		}
+		return t, nil
+	case *ast.ArrayType:
+		elem, err := g.resolve(expr.Elt)
+		if err != nil {
+			return nil, err
+		}
+		t := &typeInfo{expr: "[]" + elem.expr, elem: elem}
+		if expr.Len == nil && (elem.kind == scalarKind && elem.basic != "uint8" || elem.kind == structKind) {
+			t.kind = sliceKind
+		}
+		return t, nil
+	case *ast.SelectorExpr:
+		name := fmt.Sprintf("%s.%s", expr.X, expr.Sel.Name)
+		return &typeInfo{expr: name, maybeStruct: name != "time.Time" && name != "time.Duration"}, nil
+	case *ast.StructType:
+		return &typeInfo{expr: "struct{...}", maybeStruct: true}, nil
+	}
+	return &typeInfo{expr: fmt.Sprintf("%T", expr)}, nil
+}
+
+// supported reports whether fn can be generated for s and all the structs it
+// depends on.
+func (g *generator) supported(s *structInfo, fn function) (bool, error) {
+	switch s.support[fn] {
+	case 1, 2:
+		return true, nil
+	case -1:
+		return false, nil
+	}
+	s.support[fn] = 2
+	ok, err := g.check(s, fn)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		s.support[fn] = 1
+	} else {
+		s.support[fn] = -1
+	}
+	return ok, nil
+}
+
+func (g *generator) check(s *structInfo, fn function) (bool, error) {
+	switch fn {
+	case decodeForm, decodeQuery:
+		for _, f := range s.fields {
+			key := tagName(f, formTag(fn))
+			if key == "-" {
+				continue
+			}
+			t := f.typ
+			switch {
+			case f.embedded && t.kind != structKind:
+				return false, nil
+			case t.kind == scalarKind,
+				t.kind == pointerKind && t.elem.kind == scalarKind,
+				t.kind == sliceKind && t.elem.kind == scalarKind:
+			case t.kind == structKind, t.kind == pointerKind && t.elem.kind == structKind:
+				if t.kind == pointerKind {
+					t = t.elem
+				}
+				if ok, err := g.supported(t.strct, fn); !ok || err != nil {
+					return false, err
+				}
+			default:
+				return false, nil
+			}
+		}
+		return true, nil
+	case decodeJSON:
+		if g.methods[s.name]["UnmarshalJSON"] || g.methods[s.name]["UnmarshalText"] {
+			return false, nil
+		}
+		fields, ok := g.jsonFields(s, nil)
+		if !ok {
+			return false, nil
+		}
+		seen := map[string]bool{}
+		for _, f := range fields {
+			if seen[f.key] {
+				return false, nil // leave the rules for conflicting names to encoding/json
+			}
+			seen[f.key] = true
+			if t := structOf(f.typ); t != nil && !f.typ.unmarshaler {
+				if _, err := g.supported(t, fn); err != nil {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+	case validate:
+		for _, f := range s.fields {
+			ok, err := g.checkValidate(f)
+			if !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func formTag(fn function) string {
+	if fn == decodeQuery {
+		return "json"
+	}
+	return "form"
+}
+
+// tagName returns the name of f in the struct tag key.
+func tagName(f *field, key string) string {
+	name, _, _ := strings.Cut(f.tag.Get(key), ",")
+	if name == "" {
+		return f.name
+	}
+	return name
+}
+
+// structOf returns the struct a struct or pointer to struct type refers to.
+func structOf(t *typeInfo) *structInfo {
+	if t.kind == pointerKind {
+		t = t.elem
+	}
+	if t.kind == structKind {
+		return t.strct
+	}
+	return nil
+}
+
+// jsonField is a field of the JSON object of a struct, including the fields
+// promoted from embedded structs.
+type jsonField struct {
+	key  string
+	path string
+	typ  *typeInfo
+}
+
+func (g *generator) jsonFields(s *structInfo, path []string) ([]jsonField, bool) {
+	var fields []jsonField
+	for _, f := range s.fields {
+		tag := f.tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if hasOption(opts, "string") {
+			return nil, false
+		}
+		if f.embedded && name == "" {
+			if f.typ.kind != structKind {
+				return nil, false
+			}
+			promoted, ok := g.jsonFields(f.typ.strct, append(path, f.name))
+			if !ok {
+				return nil, false
+			}
+			fields = append(fields, promoted...)
+			continue
+		}
+		if !ast.IsExported(f.name) {
+			continue
+		}
+		if name == "" {
+			name = f.name
+		}
+		fields = append(fields, jsonField{key: name, path: strings.Join(append(path, f.name), "."), typ: f.typ})
+	}
+	return fields, true
+}
+
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// emit writes the function fn of s.
+func (g *generator) emit(w *bytes.Buffer, s *structInfo, fn function) error {
+	switch fn {
+	case decodeForm, decodeQuery:
+		g.imports["net/url"] = true
+		g.imports["github.com/go-playground/form/v4"] = true
+		fmt.Fprintf(w, "func %s(x *%s, values url.Values, prefix string, errs form.DecodeErrors) {\n", funcName(s, fn), s.name)
+		for _, f := range s.fields {
+			g.emitFormField(w, f, fn)
+		}
+	case decodeJSON:
+		g.imports["github.com/json-iterator/go"] = true
+		g.imports["go.wandrs.dev/binding/gen"] = true
+		fields, _ := g.jsonFields(s, nil)
+		fmt.Fprintf(w, "func %s(x *%s, iter *jsoniter.Iterator) {\n", funcName(s, fn), s.name)
+		w.WriteString("if iter.ReadNil() {\nreturn\n}\n")
+		if len(fields) == 0 {
+			w.WriteString("iter.Skip()\n}\n")
+			return nil
+		}
+		w.WriteString("iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {\n")
+		keys := make([]string, len(fields))
+		for i, f := range fields {
+			keys[i] = strconv.Quote(f.key)
+		}
+		fmt.Fprintf(w, "switch gen.Field(key, %s) {\n", strings.Join(keys, ", "))
+		for i, f := range fields {
+			fmt.Fprintf(w, "case %d:\n", i)
+			g.emitJSONField(w, f)
+		}
+		w.WriteString("default:\niter.Skip()\n}\nreturn true\n})\n")
+	case validate:
+		g.imports["github.com/go-playground/validator/v10"] = true
+		fmt.Fprintf(w, "func %s(x *%s, ns string, errs validator.ValidationErrors) validator.ValidationErrors {\n", funcName(s, fn), s.name)
+		for _, f := range s.fields {
+			if err := g.emitValidateField(w, f); err != nil {
+				return err
+			}
+		}
+		w.WriteString("return errs\n")
+	}
+	w.WriteString("}\n")
+	return nil
+}
+
+// emitFormField decodes f like form.Decoder does: scalars from the first value
+// of their key, slices from all values of the key and its indexed keys, and
+// nested structs from the keys prefixed with the name of the field.
+func (g *generator) emitFormField(w *bytes.Buffer, f *field, fn function) {
+	name := tagName(f, formTag(fn))
+	if name == "-" {
+		return
+	}
+	key := "prefix+" + strconv.Quote(name)
+	t := f.typ
+	switch t.kind {
+	case scalarKind:
+		fmt.Fprintf(w, "if vals := values[%s]; len(vals) > 0 {\n", key)
+		g.emitParse(w, t, "vals[0]", key, func(v string) string {
+			return fmt.Sprintf("x.%s = %s\n", f.name, v)
+		})
+		w.WriteString("}\n")
+	case pointerKind:
+		if t.elem.kind == structKind {
+			fmt.Fprintf(w, "if gen.HasPrefix(values, prefix+%q) {\n", name+".")
+			fmt.Fprintf(w, "if x.%s == nil {\nx.%s = new(%s)\n}\n", f.name, f.name, t.elem.expr)
+			fmt.Fprintf(w, "%s(x.%s, values, prefix+%q, errs)\n", g.call(t.elem.strct, fn), f.name, name+".")
+			w.WriteString("}\n")
+			g.imports["go.wandrs.dev/binding/gen"] = true
+			return
+		}
+		fmt.Fprintf(w, "if vals := values[%s]; len(vals) > 0 {\n", key)
+		g.emitParse(w, t.elem, "vals[0]", key, func(v string) string {
+			return fmt.Sprintf("p := %s\nx.%s = &p\n", v, f.name)
+		})
+		w.WriteString("}\n")
+	case sliceKind:
+		g.imports["go.wandrs.dev/binding/gen"] = true
+		fmt.Fprintf(w, "if vals := gen.Values(values, %s); len(vals) > 0 {\n", key)
+		fmt.Fprintf(w, "s := make(%s, len(vals))\n", t.expr)
+		w.WriteString("for i, val := range vals {\n")
+		g.emitParse(w, t.elem, "val", key, func(v string) string {
+			return fmt.Sprintf("s[i] = %s\n", v)
+		})
+		fmt.Fprintf(w, "}\nx.%s = s\n}\n", f.name)
+	case structKind:
+		if f.embedded {
+			fmt.Fprintf(w, "%s(&x.%s, values, prefix, errs)\n", g.call(t.strct, fn), f.name)
+		}
+		fmt.Fprintf(w, "%s(&x.%s, values, prefix+%q, errs)\n", g.call(t.strct, fn), f.name, name+".")
+	}
+}
+
+// emitParse parses the form value src into the scalar type t, reporting errors
+// for key, and writes the code set returns for the parsed value.
+func (g *generator) emitParse(w *bytes.Buffer, t *typeInfo, src, key string, set func(v string) string) {
+	convert := func(v, typ string) string {
+		if t.expr == typ {
+			return v
+		}
+		return t.expr + "(" + v + ")"
+	}
+	bits := strings.TrimLeft(t.basic, "uintfloat")
+	if bits == "" {
+		bits = "0"
+	}
+	g.imports["go.wandrs.dev/binding/gen"] = true
+	switch {
+	case t.basic == "string":
+		w.WriteString(set(convert(src, "string")))
+		return
+	case t.basic == "bool":
+		fmt.Fprintf(w, "if v, ok := gen.Bool(%s, %q, %s, errs); ok {\n", src, t.rtype, key)
+		w.WriteString(set(convert("v", "bool")))
+	case strings.HasPrefix(t.basic, "uint"):
+		fmt.Fprintf(w, "if v, ok := gen.Uint(%s, %s, %q, %s, errs); ok {\n", src, bits, t.rtype, key)
+		w.WriteString(set(convert("v", "uint64")))
+	case strings.HasPrefix(t.basic, "int"):
+		fmt.Fprintf(w, "if v, ok := gen.Int(%s, %s, %q, %s, errs); ok {\n", src, bits, t.rtype, key)
+		w.WriteString(set(convert("v", "int64")))
+	case strings.HasPrefix(t.basic, "float"):
+		fmt.Fprintf(w, "if v, ok := gen.Float(%s, %s, %q, %s, errs); ok {\n", src, bits, t.rtype, key)
+		w.WriteString(set(convert("v", "float64")))
+	}
+	w.WriteString("}\n")
+}
+
+// read returns the expression reading the scalar t from iter.
+func read(t *typeInfo) string {
+	method := "Read" + strings.ToUpper(t.basic[:1]) + t.basic[1:]
+	if t.expr == t.basic {
+		return "iter." + method + "()"
+	}
+	return t.expr + "(iter." + method + "())"
+}
+
+// emitJSONField decodes f like encoding/json does: null leaves scalars and
+// structs alone and clears pointers and slices. Types it has no code for are
+// decoded with iter.ReadVal.
+func (g *generator) emitJSONField(w *bytes.Buffer, f jsonField) {
+	t, x := f.typ, "x."+f.path
+	generated := func(t *typeInfo) bool {
+		return t.kind == scalarKind && !t.unmarshaler ||
+			t.kind == structKind && !t.unmarshaler && t.strct.support[decodeJSON] == 1
+	}
+	switch {
+	case generated(t) && t.kind == scalarKind:
+		fmt.Fprintf(w, "if !iter.ReadNil() {\n%s = %s\n}\n", x, read(t))
+	case generated(t):
+		fmt.Fprintf(w, "%s(&%s, iter)\n", g.call(t.strct, decodeJSON), x)
+	case t.kind == pointerKind && generated(t.elem):
+		fmt.Fprintf(w, "if iter.ReadNil() {\n%s = nil\n} else {\n", x)
+		if t.elem.kind == scalarKind {
+			fmt.Fprintf(w, "p := %s\n%s = &p\n", read(t.elem), x)
+		} else {
+			fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", x, x, t.elem.expr)
+			fmt.Fprintf(w, "%s(%s, iter)\n", g.call(t.elem.strct, decodeJSON), x)
+		}
+		w.WriteString("}\n")
+	case t.kind == sliceKind && generated(t.elem):
+		fmt.Fprintf(w, "if iter.ReadNil() {\n%s = nil\n} else {\n", x)
+		fmt.Fprintf(w, "s := %s{}\n", t.expr)
+		w.WriteString("iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {\n")
+		fmt.Fprintf(w, "var v %s\n", t.elem.expr)
+		if t.elem.kind == scalarKind {
+			fmt.Fprintf(w, "if !iter.ReadNil() {\nv = %s\n}\n", read(t.elem))
+		} else {
+			fmt.Fprintf(w, "%s(&v, iter)\n", g.call(t.elem.strct, decodeJSON))
+		}
+		fmt.Fprintf(w, "s = append(s, v)\nreturn true\n})\n%s = s\n}\n", x)
+	default:
+		fmt.Fprintf(w, "iter.ReadVal(&%s)\n", x)
+	}
+}
+
+// validateTag is a single tag of a validate struct tag, like min=3.
+type validateTag struct {
+	name, param string
+}
+
+// parseValidate splits the validate tag of f at dive.
+func parseValidate(f *field) (tags, dive []validateTag, hasDive, ok bool) {
+	v := f.tag.Get("validate")
+	if v == "" {
+		return nil, nil, false, true
+	}
+	if strings.ContainsAny(v, "|'") || strings.Contains(v, "0x2C") {
+		return nil, nil, false, false
+	}
+	for i, s := range strings.Split(v, ",") {
+		name, param, _ := strings.Cut(s, "=")
+		switch name {
+		case "dive":
+			if hasDive {
+				return nil, nil, false, false
+			}
+			hasDive = true
+			continue
+		case "omitempty":
+			if i > 0 && !(hasDive && len(dive) == 0) {
+				return nil, nil, false, false
+			}
+		case "required", "min", "max", "len", "eq", "ne", "gt", "gte", "lt", "lte", "oneof":
+		default:
+			return nil, nil, false, false
+		}
+		if hasDive {
+			dive = append(dive, validateTag{name, param})
+		} else {
+			tags = append(tags, validateTag{name, param})
+		}
+	}
+	return tags, dive, hasDive, true
+}
+
+// checkValidate reports whether the validate tag of f is supported.
+func (g *generator) checkValidate(f *field) (bool, error) {
+	if f.tag.Get("validate") == "-" {
+		return true, nil
+	}
+	tags, dive, hasDive, ok := parseValidate(f)
+	if !ok {
+		return false, nil
+	}
+	t := f.typ
+	switch t.kind {
+	case scalarKind, pointerKind, sliceKind, structKind:
+	default:
+		return f.tag.Get("validate") == "" && !t.maybeStruct, nil
+	}
+	if hasDive && t.kind != sliceKind {
+		return false, nil
+	}
+	if s := structOf(t); s != nil {
+		return g.supported(s, validate)
+	}
+	if t.kind == sliceKind {
+		if _, err := conditions(t, "v", omitEmpty(tags)); err != nil {
+			return false, err
+		}
+		if t.elem.kind == structKind {
+			if !hasDive {
+				return true, nil
+			}
+			if len(dive) > 0 {
+				return false, nil
+			}
+			return g.supported(t.elem.strct, validate)
+		}
+		_, err := conditions(t.elem, "v", omitEmpty(dive))
+		return err == nil, err
+	}
+	if t.kind == pointerKind {
+		t, tags = t.elem, dereferenced(tags)
+	}
+	_, err := conditions(t, "v", omitEmpty(tags))
+	return err == nil, err
+}
+
+// omitEmpty drops a leading omitempty from tags.
+func omitEmpty(tags []validateTag) []validateTag {
+	if len(tags) > 0 && tags[0].name == "omitempty" {
+		return tags[1:]
+	}
+	return tags
+}
+
+// dereferenced returns the tags checked on the value of a non-nil pointer, for
+// which required always passes and omitempty never skips.
+func dereferenced(tags []validateTag) []validateTag {
+	deref := make([]validateTag, 0, len(tags))
+	for _, tag := range tags {
+		if tag.name != "omitempty" && tag.name != "required" {
+			deref = append(deref, tag)
+		}
+	}
+	return deref
+}
+
+// emitValidateField checks f like validator.Validate does, stopping at the first
+// tag that fails.
+func (g *generator) emitValidateField(w *bytes.Buffer, f *field) error {
+	if f.tag.Get("validate") == "-" {
+		return nil
+	}
+	tags, dive, hasDive, _ := parseValidate(f)
+	t, x, name := f.typ, "x."+f.name, strconv.Quote(f.name)
+	switch t.kind {
+	case structKind:
+		fmt.Fprintf(w, "errs = %s(&%s, ns+%q, errs)\n", g.call(t.strct, validate), x, "."+f.name)
+	case pointerKind:
+		if len(tags) > 0 && tags[0].name != "omitempty" {
+			g.imports["go.wandrs.dev/binding/gen"] = true
+			fmt.Fprintf(w, "if %s == nil {\n", x)
+			fmt.Fprintf(w, "errs = gen.Fail(errs, ns, %s, %q, %q, %s)\n", name, tags[0].name, tags[0].param, x)
+			w.WriteString("} else {\n")
+		} else {
+			fmt.Fprintf(w, "if %s != nil {\n", x)
+		}
+		if t.elem.kind == structKind {
+			fmt.Fprintf(w, "errs = %s(%s, ns+%q, errs)\n", g.call(t.elem.strct, validate), x, "."+f.name)
+		} else if err := g.emitConditions(w, t.elem, "*"+x, name, dereferenced(tags), ""); err != nil {
+			return err
+		}
+		w.WriteString("}\n")
+	case scalarKind:
+		return g.emitConditions(w, t, x, name, tags, "")
+	case sliceKind:
+		body := ""
+		if hasDive {
+			var elem bytes.Buffer
+			g.imports["strconv"] = true
+			elemName := fmt.Sprintf("%q+strconv.Itoa(i)+\"]\"", f.name+"[")
+			fmt.Fprintf(&elem, "for i := range %s {\n", x)
+			if t.elem.kind == structKind {
+				fmt.Fprintf(&elem, "errs = %s(&%s[i], ns+%q+strconv.Itoa(i)+\"]\", errs)\n", g.call(t.elem.strct, validate), x, "."+f.name+"[")
+			} else if err := g.emitConditions(&elem, t.elem, x+"[i]", elemName, dive, ""); err != nil {
+				return err
+			}
+			elem.WriteString("}\n")
+			body = elem.String()
+		}
+		return g.emitConditions(w, t, x, name, tags, body)
+	}
+	return nil
+}
+
+// emitConditions writes the checks of tags on the value v of the scalar or
+// slice type t, followed by body if they all pass.
+func (g *generator) emitConditions(w *bytes.Buffer, t *typeInfo, v, name string, tags []validateTag, body string) error {
+	omitempty := len(tags) > 0 && tags[0].name == "omitempty"
+	tags = omitEmpty(tags)
+	conds, err := conditions(t, v, tags)
+	if err != nil {
+		return err
+	}
+	if len(conds) == 0 && body == "" {
+		return nil
+	}
+	if omitempty {
+		fmt.Fprintf(w, "if %s {\n", nonZero(t, v))
+	}
+	for i, cond := range conds {
+		if i > 0 {
+			w.WriteString("} else ")
+		}
+		g.imports["go.wandrs.dev/binding/gen"] = true
+		if strings.Contains(cond, "utf8.") {
+			g.imports["unicode/utf8"] = true
+		}
+		fmt.Fprintf(w, "if %s {\n", cond)
+		fmt.Fprintf(w, "errs = gen.Fail(errs, ns, %s, %q, %q, %s)\n", name, tags[i].name, tags[i].param, v)
+	}
+	if len(conds) > 0 && body != "" {
+		w.WriteString("} else {\n")
+	}
+	w.WriteString(body)
+	if len(conds) > 0 {
+		w.WriteString("}\n")
+	}
+	if omitempty {
+		w.WriteString("}\n")
+	}
+	return nil
+}
+
+// nonZero returns the condition that v of type t has a value in the sense of
+// the required tag.
+func nonZero(t *typeInfo, v string) string {
+	switch {
+	case t.kind == sliceKind:
+		return v + " != nil"
+	case t.basic == "string":
+		return v + ` != ""`
+	case t.basic == "bool":
+		return v
+	}
+	return v + " != 0"
+}
+
+// zero returns the condition that v of type t fails the required tag.
+func zero(t *typeInfo, v string) string {
+	switch {
+	case t.kind == sliceKind:
+		return v + " == nil"
+	case t.basic == "string":
+		return v + ` == ""`
+	case t.basic == "bool":
+		return "!" + v
+	}
+	return v + " == 0"
+}
+
+// conditions returns for each tag the condition under which v of type t fails it.
+func conditions(t *typeInfo, v string, tags []validateTag) ([]string, error) {
+	conds := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag.name == "required" {
+			conds = append(conds, zero(t, v))
+			continue
+		}
+		if t.kind == scalarKind && t.basic == "bool" || tag.name == "omitempty" {
+			return nil, fmt.Errorf("validate tag %s is not supported on %s", tag.name, t.expr)
+		}
+
+		length, number := v, tag.param
+		switch {
+		case t.kind == sliceKind:
+			length = "len(" + v + ")"
+		case t.basic == "string" && t.expr == "string":
+			length = "utf8.RuneCountInString(" + v + ")"
+		case t.basic == "string":
+			length = "utf8.RuneCountInString(string(" + v + "))"
+		}
+		parse := func(s string) error {
+			var err error
+			switch {
+			case t.kind == sliceKind || t.basic == "string" || strings.HasPrefix(t.basic, "int"):
+				_, err = strconv.ParseInt(s, 10, 64)
+			case strings.HasPrefix(t.basic, "uint"):
+				_, err = strconv.ParseUint(s, 10, 64)
+			default:
+				_, err = strconv.ParseFloat(s, 64)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid parameter of validate tag %s=%s on %s", tag.name, s, t.expr)
+			}
+			return nil
+		}
+
+		var cond string
+		switch tag.name {
+		case "oneof":
+			if t.kind == sliceKind {
+				return nil, fmt.Errorf("validate tag oneof is not supported on %s", t.expr)
+			}
+			var alts []string
+			for _, p := range strings.Fields(tag.param) {
+				if t.basic == "string" {
+					p = strconv.Quote(p)
+				} else if err := parse(p); err != nil {
+					return nil, err
+				}
+				alts = append(alts, v+" == "+p)
+			}
+			conds = append(conds, "!("+strings.Join(alts, " || ")+")")
+			continue
+		case "eq", "ne":
+			if t.kind == scalarKind && t.basic == "string" {
+				length, number = v, strconv.Quote(tag.param)
+				break
+			}
+			fallthrough
+		default:
+			if err := parse(tag.param); err != nil {
+				return nil, err
+			}
+		}
+		switch tag.name {
+		case "min", "gte":
+			cond = length + " < " + number
+		case "max", "lte":
+			cond = length + " > " + number
+		case "len", "eq":
+			cond = length + " != " + number
+		case "ne":
+			cond = length + " == " + number
+		case "gt":
+			cond = length + " <= " + number
+		case "lt":
+			cond = length + " >= " + number
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTestModels(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "testmodels")
+	name, files, err := parsePackage(dir, "person_binding.go")
+	require.NoError(t, err)
+	src, err := generate(name, files, []string{"Person"}, "bindinggen -type Person")
+	require.NoError(t, err)
+
+	want, err := os.ReadFile(filepath.Join(dir, "person_binding.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(src), "person_binding.go is out of date, run go generate ./internal/testmodels")
+}
+
+func TestGenerateFallback(t *testing.T) {
+	const model = `package models
+
+type Signup struct {
+	Email   string            ` + "`" + `form:"email" json:"email" validate:"required,email"` + "`" + `
+	Profile Profile           ` + "`" + `form:"profile" json:"profile"` + "`" + `
+	Labels  map[string]string ` + "`" + `form:"-" json:"labels"` + "`" + `
+}
+
+type Profile struct {
+	Bio     string ` + "`" + `json:"bio,string"` + "`" + `
+	private int
+}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "models.go", model, 0)
+	require.NoError(t, err)
+	src, err := generate("models", []*ast.File{f}, []string{"Signup"}, "bindinggen -type Signup")
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.Contains(t, out, "DecodeForm: _Signup_decodeForm,")
+	assert.NotContains(t, out, "DecodeQuery:", "labels can not be decoded from a query")
+	assert.Contains(t, out, "DecodeJSON: _Signup_decodeJSON,")
+	assert.Contains(t, out, "iter.ReadVal(&x.Profile)", "the string option is left to encoding/json")
+	assert.NotContains(t, out, "Validate:", "the email tag is not generated")
+	assert.False(t, strings.Contains(out, "private"))
+
+	_, err = generate("models", []*ast.File{f}, []string{"Missing"}, "bindinggen -type Missing")
+	assert.Error(t, err)
+}
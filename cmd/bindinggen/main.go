@@ -0,0 +1,106 @@
+// Command bindinggen generates reflection free binders for the model structs of
+// a package. For every type named by -type it generates functions that decode
+// the model from url.Values using the form and the json tags, decode it from
+// JSON and check its validate tags, and registers them with
+// binding.RegisterGenerated, so that Bind, Form, MultipartForm and JSON use
+// them instead of reflection.
+//
+// It is meant to be run by go generate from the package of the models:
+//
+//	//go:generate go run go.wandrs.dev/binding/cmd/bindinggen -type Person,Address
+//
+// Nested structs of the same package are generated as well. Fields or validate
+// tags bindinggen does not support leave the corresponding function out, and
+// binding falls back to reflection for it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of type names; must be set")
+	output    = flag.String("output", "", "output file name; default srcdir/<type>_binding.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of bindinggen:\n")
+	fmt.Fprintf(os.Stderr, "\tbindinggen [flags] -type T [directory]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bindinggen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if len(*typeNames) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	dir := "."
+	if args := flag.Args(); len(args) == 1 {
+		dir = args[0]
+	} else if len(args) > 1 {
+		log.Fatal("only one directory at a time")
+	}
+
+	outputName := *output
+	if outputName == "" {
+		outputName = strings.ToLower(types[0]) + "_binding.go"
+	}
+	if !filepath.IsAbs(outputName) {
+		outputName = filepath.Join(dir, outputName)
+	}
+
+	name, files, err := parsePackage(dir, filepath.Base(outputName))
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(name, files, types, "bindinggen "+strings.Join(os.Args[1:], " "))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(outputName, src, 0o644); err != nil {
+		log.Fatalf("writing output: %s", err)
+	}
+}
+
+// parsePackage parses the Go files of the package in dir, skipping test files
+// and the previously generated output.
+func parsePackage(dir, output string) (string, []*ast.File, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	want := os.Getenv("GOPACKAGE")
+	for name, pkg := range pkgs {
+		if want != "" && name != want {
+			continue
+		}
+		if len(pkgs) > 1 && want == "" {
+			return "", nil, fmt.Errorf("%s holds more than one package, run bindinggen from go generate", dir)
+		}
+		files := make([]*ast.File, 0, len(pkg.Files))
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+		return name, files, nil
+	}
+	return "", nil, fmt.Errorf("no Go package found in %s", dir)
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	bindingPath = "go.wandrs.dev/binding"
+	metav1Path  = "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// binders are the functions whose first argument is a model.
+var binders = map[string]bool{
+	"Bind":          true,
+	"Form":          true,
+	"MultipartForm": true,
+	"JSON":          true,
+}
+
+// typedPackage is a type checked package.
+type typedPackage struct {
+	fset  *token.FileSet
+	files []*ast.File
+	types *types.Package
+	info  *types.Info
+	imp   types.ImporterFrom
+	dir   string
+}
+
+// loadPackage parses and type checks the package in dir, importing its
+// dependencies from source.
+func loadPackage(dir string) (*typedPackage, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &typedPackage{
+		fset: token.NewFileSet(),
+		info: &types.Info{
+			Types: map[ast.Expr]types.TypeAndValue{},
+			Uses:  map[*ast.Ident]types.Object{},
+			Defs:  map[*ast.Ident]types.Object{},
+		},
+		dir: dir,
+	}
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(pkg.fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		pkg.files = append(pkg.files, f)
+	}
+	// the source importer resolves imports with go list, which has to run in
+	// the module of the package rather than in the working directory
+	build.Default.Dir = dir
+	pkg.imp = importer.ForCompiler(pkg.fset, "source", nil).(types.ImporterFrom)
+	conf := types.Config{Importer: pkg.imp}
+	pkg.types, err = conf.Check(bp.ImportPath, pkg.fset, pkg.files, pkg.info)
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+type generator struct {
+	names map[*types.TypeName]string // TypeScript names of the declared Go types
+	taken map[string]bool
+	queue []types.Type // named types to declare
+	seen  map[string]bool
+}
+
+// generate returns the TypeScript declarations of the models and results of
+// the handlers of pkg, and of metav1.Status.
+func generate(pkg *typedPackage) ([]byte, error) {
+	g := &generator{
+		names: map[*types.TypeName]string{},
+		taken: map[string]bool{},
+		seen:  map[string]bool{},
+	}
+
+	for _, f := range pkg.files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			fn, ok := pkg.info.Uses[sel.Sel].(*types.Func)
+			if !ok || fn.Pkg() == nil || fn.Pkg().Path() != bindingPath {
+				return true
+			}
+			arg := pkg.info.TypeOf(call.Args[0])
+			switch {
+			case arg == nil:
+			case binders[fn.Name()]:
+				g.root(arg)
+			case fn.Name() == "HandlerFunc":
+				if sig, ok := arg.Underlying().(*types.Signature); ok {
+					if t := resultType(sig); t != nil {
+						g.root(t)
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	meta, err := pkg.imp.ImportFrom(metav1Path, pkg.dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	g.root(meta.Scope().Lookup("Status").Type())
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by bindingts. DO NOT EDIT.\n")
+	for i := 0; i < len(g.queue); i++ {
+		buf.WriteString("\n")
+		g.declare(&buf, g.queue[i].(*types.Named))
+	}
+	return buf.Bytes(), nil
+}
+
+// resultType returns the type of the value a HandlerFunc writes as JSON, or nil
+// if it returns nothing, an error or a body written as is.
+func resultType(sig *types.Signature) types.Type {
+	results := sig.Results()
+	if results.Len() == 0 || results.Len() > 2 {
+		return nil
+	}
+	t := results.At(0).Type()
+	if results.Len() == 1 && types.Implements(t, errorType) {
+		return nil
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok && isBinding(named.Obj(), "Response") && named.TypeArgs().Len() == 1 {
+		t = named.TypeArgs().At(0)
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if isByte(u.Elem()) {
+			return nil // written as is
+		}
+	case *types.Chan:
+		t = u.Elem()
+	case *types.Signature:
+		// func(yield func(T) bool) is streamed like a channel
+		if u.Params().Len() == 1 && u.Results().Len() == 0 {
+			if yield, ok := u.Params().At(0).Type().Underlying().(*types.Signature); ok && yield.Params().Len() == 1 {
+				t = yield.Params().At(0).Type()
+			}
+		}
+	}
+	if hasMethod(t, "Read") || hasMethod(t, "ResultChan") {
+		return nil // io.Reader and watch.Interface
+	}
+	if named, ok := t.(*types.Named); ok && (isBinding(named.Obj(), "Event") || named.Obj().Name() == "Event" && named.Obj().Pkg() != nil && strings.HasSuffix(named.Obj().Pkg().Path(), "/watch")) {
+		return nil // server-sent and watch events
+	}
+	return t
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func isBinding(obj *types.TypeName, name string) bool {
+	return obj.Pkg() != nil && obj.Pkg().Path() == bindingPath && obj.Name() == name
+}
+
+func isByte(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+// isNillable reports whether values of type t can be nil, which encoding/json
+// writes as null.
+func isNillable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map:
+		return true
+	}
+	return false
+}
+
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// root queues the declaration of the model t.
+func (g *generator) root(t types.Type) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	g.tsType(t)
+}
+
+// tsType returns the TypeScript type of t, queueing the declaration of the
+// named types it refers to.
+func (g *generator) tsType(t types.Type) string {
+	switch t := t.(type) {
+	case *types.Named:
+		if ts, ok := g.wellKnown(t); ok {
+			return ts
+		}
+		switch t.Underlying().(type) {
+		case *types.Struct:
+			return g.declared(t)
+		case *types.Basic:
+			if hasMethod(t, "MarshalJSON") {
+				return "unknown"
+			}
+			return g.declared(t)
+		}
+		return g.tsType(t.Underlying())
+	case *types.Pointer:
+		return g.tsType(t.Elem())
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return "boolean"
+		case t.Info()&types.IsNumeric != 0:
+			return "number"
+		case t.Info()&types.IsString != 0:
+			return "string"
+		}
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			return "string" // base64
+		}
+		return array(g.tsType(t.Elem()))
+	case *types.Array:
+		return array(g.tsType(t.Elem()))
+	case *types.Map:
+		return "Record<string, " + g.tsType(t.Elem()) + ">"
+	case *types.Struct:
+		var buf bytes.Buffer
+		buf.WriteString("{\n")
+		g.properties(&buf, t, "    ")
+		buf.WriteString("  }")
+		return buf.String()
+	}
+	return "unknown"
+}
+
+func array(elem string) string {
+	if strings.ContainsAny(elem, " |") {
+		return "(" + elem + ")[]"
+	}
+	return elem + "[]"
+}
+
+// wellKnown returns the TypeScript type of the types with custom JSON encodings.
+func (g *generator) wellKnown(t *types.Named) (string, bool) {
+	obj := t.Obj()
+	if obj.Pkg() == nil {
+		return "", false
+	}
+	switch obj.Pkg().Path() + "." + obj.Name() {
+	case "time.Time", metav1Path + ".Time", metav1Path + ".MicroTime", metav1Path + ".Duration":
+		return "string", true
+	case "time.Duration":
+		return "number", true
+	case "encoding/json.RawMessage", "k8s.io/apimachinery/pkg/runtime.RawExtension":
+		return "unknown", true
+	case "k8s.io/apimachinery/pkg/util/intstr.IntOrString":
+		return "number | string", true
+	}
+	if _, ok := t.Underlying().(*types.Struct); ok && hasMethod(t, "MarshalText") {
+		return "string", true
+	}
+	if _, ok := t.Underlying().(*types.Struct); ok && hasMethod(t, "MarshalJSON") {
+		return "unknown", true
+	}
+	return "", false
+}
+
+// declared returns the TypeScript name of t and queues its declaration.
+func (g *generator) declared(t *types.Named) string {
+	obj := t.Obj()
+	key := types.TypeString(t, nil)
+	if name, ok := g.names[obj]; ok && t.TypeArgs().Len() == 0 {
+		return name
+	}
+	name := obj.Name()
+	for i := 0; i < t.TypeArgs().Len(); i++ {
+		name += identifier(g.tsType(t.TypeArgs().At(i)))
+	}
+	if !g.seen[key] {
+		if g.taken[name] && obj.Pkg() != nil {
+			name = identifier(obj.Pkg().Name()) + name
+		}
+		g.seen[key] = true
+		g.taken[name] = true
+		g.queue = append(g.queue, t)
+	}
+	if t.TypeArgs().Len() == 0 {
+		g.names[obj] = name
+	}
+	return name
+}
+
+// identifier turns a TypeScript type into a part of a name, like Post[] into PostArray.
+func identifier(s string) string {
+	s = strings.ReplaceAll(s, "[]", "Array")
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// declare writes the declaration of t.
+func (g *generator) declare(w *bytes.Buffer, t *types.Named) {
+	name := g.declared(t)
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		fmt.Fprintf(w, "export interface %s {\n", name)
+		g.properties(w, u, "  ")
+		w.WriteString("}\n")
+	default:
+		fmt.Fprintf(w, "export type %s = %s;\n", name, g.tsType(u))
+	}
+}
+
+// property is a field of the JSON object of a struct.
+type property struct {
+	name     string
+	typ      string
+	optional bool
+	nullable bool
+	depth    int
+	tagged   bool
+}
+
+// properties writes the properties of the JSON object of st, including those
+// promoted from embedded structs like encoding/json does.
+func (g *generator) properties(w *bytes.Buffer, st *types.Struct, indent string) {
+	var props []*property
+	index := map[string]int{}
+	var collect func(st *types.Struct, depth int, visited map[*types.Struct]bool)
+	collect = func(st *types.Struct, depth int, visited map[*types.Struct]bool) {
+		if visited[st] {
+			return
+		}
+		visited[st] = true
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			tag := reflect.StructTag(st.Tag(i))
+			jsonTag := tag.Get("json")
+			if jsonTag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(jsonTag, ",")
+			ft := f.Type()
+			ptr, isPtr := ft.(*types.Pointer)
+			if f.Embedded() && name == "" {
+				elem := ft
+				if isPtr {
+					elem = ptr.Elem()
+				}
+				if embedded, ok := elem.Underlying().(*types.Struct); ok {
+					if _, custom := g.wellKnownType(elem); !custom {
+						collect(embedded, depth+1, visited)
+						continue
+					}
+				}
+			}
+			if !f.Exported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name()
+			}
+
+			required := isRequired(tag)
+			omitempty := hasOption(jsonTag, "omitempty")
+			p := &property{
+				name:     name,
+				optional: omitempty && !required,
+				nullable: isNillable(ft) && !omitempty && !required,
+				depth:    depth,
+				tagged:   jsonTag != "" && !strings.HasPrefix(jsonTag, ","),
+			}
+			if hasOption(jsonTag, "string") {
+				p.typ = "string"
+			} else {
+				p.typ = g.tsType(ft)
+			}
+
+			// the shallowest field wins, a tagged one if there are several
+			if i, ok := index[name]; ok {
+				prev := props[i]
+				if prev.depth < depth || prev.depth == depth && (prev.tagged || !p.tagged) {
+					continue
+				}
+				props[i] = p
+				continue
+			}
+			index[name] = len(props)
+			props = append(props, p)
+		}
+		delete(visited, st)
+	}
+	collect(st, 0, map[*types.Struct]bool{})
+
+	for _, p := range props {
+		name := p.name
+		if !isIdentifier(name) {
+			name = strconv.Quote(name)
+		}
+		opt := ""
+		if p.optional {
+			opt = "?"
+		}
+		typ := p.typ
+		if p.nullable {
+			typ += " | null"
+		}
+		fmt.Fprintf(w, "%s%s%s: %s;\n", indent, name, opt, typ)
+	}
+}
+
+// wellKnownType is wellKnown for any type.
+func (g *generator) wellKnownType(t types.Type) (string, bool) {
+	if named, ok := t.(*types.Named); ok {
+		return g.wellKnown(named)
+	}
+	return "", false
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && r != '$' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isRequired reports whether the validate tag requires the field, see SchemaOf.
+func isRequired(tag reflect.StructTag) bool {
+	for _, v := range strings.Split(tag.Get("validate"), ",") {
+		if v == "dive" {
+			break
+		}
+		if v == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
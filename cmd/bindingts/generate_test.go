@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	// testdata/api is a module of its own whose dependencies are small stubs,
+	// so that loading it does not need the module cache or the network
+	t.Setenv("GOWORK", "off")
+	pkg, err := loadPackage(filepath.Join("testdata", "api"))
+	require.NoError(t, err)
+	src, err := generate(pkg)
+	require.NoError(t, err)
+	out := string(src)
+
+	assert.Contains(t, out, `export interface Post {
+  id: number;
+  created: string;
+  title: string;
+  body: string | null;
+  tags?: string[];
+  author: User;
+  labels: Record<string, string> | null;
+  views: string;
+}
+`)
+	assert.Contains(t, out, `export interface User {
+  name: string;
+  "e-mail"?: string;
+  Role: Role;
+}
+`)
+	assert.Contains(t, out, "export type Role = string;\n")
+	assert.Contains(t, out, "export interface Comment {\n  text: string;\n}\n", "the body of a Response is generated")
+	assert.NotContains(t, out, "Response")
+	assert.NotContains(t, out, "Event", "streamed bodies are not JSON")
+	assert.NotContains(t, out, "Hidden")
+	assert.NotContains(t, out, "private")
+
+	assert.Contains(t, out, "export interface Status {\n")
+	assert.Contains(t, out, "  causes?: StatusCause[];\n")
+	assert.Contains(t, out, "export interface StatusCause {\n  reason?: CauseType;\n  message?: string;\n  field?: string;\n}\n")
+}
@@ -0,0 +1,61 @@
+// Command bindingts generates TypeScript interfaces for the models of a package
+// that are bound with Bind, Form, MultipartForm or JSON, for the values its
+// HandlerFunc functions return and for metav1.Status, the body of every error
+// response, so that web clients do not have to duplicate them.
+//
+// It can be run by go generate from the package of the handlers:
+//
+//	//go:generate go run go.wandrs.dev/binding/cmd/bindingts -output ../web/src/api.ts
+//
+// Properties follow the json tags of the Go fields. Like encoding/json writes
+// them, a property is optional if its field has the omitempty option, and a
+// pointer, slice or map without it may be null. A required validate tag makes
+// a property neither optional nor nullable.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+var output = flag.String("output", "", "output file name; default standard output")
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of bindingts:\n")
+	fmt.Fprintf(os.Stderr, "\tbindingts [flags] [directory]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bindingts: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	dir := "."
+	if args := flag.Args(); len(args) == 1 {
+		dir = args[0]
+	} else if len(args) > 1 {
+		log.Fatal("only one directory at a time")
+	}
+
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0o644)
+	}
+	if err != nil {
+		log.Fatalf("writing output: %s", err)
+	}
+}
@@ -0,0 +1,48 @@
+// Package api is the input of the bindingts tests.
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"go.wandrs.dev/binding"
+)
+
+type Meta struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type Post struct {
+	Meta
+	Title   string            `json:"title" validate:"required"`
+	Body    *string           `json:"body"`
+	Tags    []string          `json:"tags,omitempty"`
+	Author  *User             `json:"author,omitempty" validate:"required"`
+	Labels  map[string]string `json:"labels"`
+	Views   int64             `json:"views,string"`
+	Hidden  string            `json:"-"`
+	private string
+}
+
+type User struct {
+	Name  string `json:"name"`
+	Email string `json:"e-mail,omitempty"`
+	Role  Role
+}
+
+type Role string
+
+type Comment struct {
+	Text string `json:"text"`
+}
+
+func Register(r interface {
+	Post(string, http.HandlerFunc)
+}) {
+	r.Post("/posts", binding.JSON(Post{})(nil).ServeHTTP)
+	r.Post("/get", binding.HandlerFunc(func() (*binding.Response[[]Comment], error) { return nil, nil }))
+	r.Post("/raw", binding.HandlerFunc(func() (io.Reader, error) { return nil, nil }))
+	r.Post("/events", binding.HandlerFunc(func() (<-chan binding.Event, error) { return nil, nil }))
+}
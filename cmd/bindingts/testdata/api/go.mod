@@ -0,0 +1,13 @@
+module example.com/api
+
+go 1.19
+
+require (
+	go.wandrs.dev/binding v0.0.0
+	k8s.io/apimachinery v0.0.0
+)
+
+replace (
+	go.wandrs.dev/binding => ../binding
+	k8s.io/apimachinery => ../apimachinery
+)
@@ -0,0 +1,27 @@
+// Package v1 declares the parts of k8s.io/apimachinery/pkg/apis/meta/v1 that
+// the bindingts tests use, so that they do not have to load its dependencies.
+package v1
+
+type Status struct {
+	Status  string         `json:"status,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Reason  StatusReason   `json:"reason,omitempty"`
+	Details *StatusDetails `json:"details,omitempty"`
+	Code    int32          `json:"code,omitempty"`
+}
+
+type StatusReason string
+
+type StatusDetails struct {
+	Name   string        `json:"name,omitempty"`
+	Kind   string        `json:"kind,omitempty"`
+	Causes []StatusCause `json:"causes,omitempty"`
+}
+
+type StatusCause struct {
+	Type    CauseType `json:"reason,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Field   string    `json:"field,omitempty"`
+}
+
+type CauseType string
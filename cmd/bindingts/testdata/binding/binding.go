@@ -0,0 +1,21 @@
+// Package binding declares the parts of go.wandrs.dev/binding that the bindingts
+// tests use, so that they do not have to load its dependencies.
+package binding
+
+import "net/http"
+
+type Response[T any] struct {
+	Body T
+}
+
+type Event struct {
+	Data interface{}
+}
+
+func JSON(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+	return nil
+}
+
+func HandlerFunc(fn interface{}) http.HandlerFunc {
+	return nil
+}
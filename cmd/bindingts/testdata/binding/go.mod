@@ -0,0 +1,3 @@
+module go.wandrs.dev/binding
+
+go 1.19
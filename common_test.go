@@ -17,6 +17,12 @@ package binding_test
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/unrolled/render"
 )
 
 const (
@@ -81,3 +87,32 @@ func (p Post) Model() string {
 func (g Group) Model() string {
 	return g.Name
 }
+
+// benchmarkBinder serves the requests of newRequest with binder binding a
+// BlogPost. The cached sub-benchmark creates the middleware once, like
+// applications do. The uncached one creates it for every request, which
+// rebuilds the binding plan and its form decoders the way every request did
+// before the plans were cached, so that both can be compared with benchstat.
+func benchmarkBinder(b *testing.B, binder binderFunc, newRequest func() *http.Request) {
+	injector := binding.Injector(render.New())
+	handler := binding.HandlerFunc(func(actual BlogPost) []byte {
+		return nil
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		h := injector(binder(BlogPost{})(handler))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.ServeHTTP(httptest.NewRecorder(), newRequest())
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h := injector(binder(BlogPost{})(handler))
+			h.ServeHTTP(httptest.NewRecorder(), newRequest())
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package binding
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// errorMapping converts errors it matches to an API error.
+type errorMapping func(err error) (*apierrors.StatusError, bool)
+
+var errorMappings []errorMapping
+
+// RegisterError writes errors that match target with errors.Is as the error
+// returned by fn, instead of a 500 metav1.Status, for example
+//
+//	binding.RegisterError(sql.ErrNoRows, func(err error) *apierrors.StatusError {
+//		return apierrors.NewNotFound(schema.GroupResource{}, "")
+//	})
+//
+// Mappings are tried in the order they are registered. RegisterError is not
+// safe to call while serving requests.
+func RegisterError(target error, fn func(err error) *apierrors.StatusError) {
+	errorMappings = append(errorMappings, func(err error) (*apierrors.StatusError, bool) {
+		if errors.Is(err, target) {
+			return fn(err), true
+		}
+		return nil, false
+	})
+}
+
+// RegisterErrorType writes errors that match the error type E with errors.As
+// as the error returned by fn, like RegisterError does for sentinel errors.
+func RegisterErrorType[E error](fn func(err E) *apierrors.StatusError) {
+	errorMappings = append(errorMappings, func(err error) (*apierrors.StatusError, bool) {
+		var target E
+		if errors.As(err, &target) {
+			return fn(target), true
+		}
+		return nil, false
+	})
+}
+
+// mapError returns the API error err is written as. An err that already is an
+// API error is returned as is. Otherwise the registered mappings are tried, and
+// then a *apierrors.StatusError wrapped in err is returned in place of err.
+func mapError(err error) error {
+	if isNil(err) {
+		return err
+	}
+	if _, ok := err.(apierrors.APIStatus); ok {
+		return err
+	}
+	for _, m := range errorMappings {
+		if mapped, ok := m(err); ok && mapped != nil {
+			return mapped
+		}
+	}
+	var status *apierrors.StatusError
+	if errors.As(err, &status) {
+		return status
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package binding_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/inject"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("conflict")
+)
+
+type quotaError struct {
+	limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota of %d exceeded", e.limit)
+}
+
+func TestRegisterError(t *testing.T) {
+	binding.RegisterError(errNotFound, func(err error) *apierrors.StatusError {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "people"}, "")
+	})
+	binding.RegisterError(errConflict, func(err error) *apierrors.StatusError {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "people"}, "", err)
+	})
+	binding.RegisterErrorType(func(err *quotaError) *apierrors.StatusError {
+		return apierrors.NewTooManyRequests(err.Error(), 10)
+	})
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		code    int
+	}{
+		{
+			name:    "sentinel",
+			handler: binding.HandlerFunc(func() error { return errNotFound }),
+			code:    http.StatusNotFound,
+		},
+		{
+			name:    "wrapped sentinel",
+			handler: binding.HandlerFunc(func() (Person, error) { return Person{}, fmt.Errorf("update: %w", errConflict) }),
+			code:    http.StatusConflict,
+		},
+		{
+			name:    "error type",
+			handler: binding.HandlerFunc(func() error { return fmt.Errorf("create: %w", &quotaError{limit: 3}) }),
+			code:    http.StatusTooManyRequests,
+		},
+		{
+			name: "wrapped StatusError",
+			handler: binding.HandlerFunc(func() error {
+				return fmt.Errorf("lookup: %w", apierrors.NewForbidden(schema.GroupResource{Resource: "people"}, "john", errors.New("denied")))
+			}),
+			code: http.StatusForbidden,
+		},
+		{
+			name:    "unmapped",
+			handler: binding.HandlerFunc(func() error { return errors.New("boom") }),
+			code:    http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.Get("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+
+	t.Run("Inject", func(t *testing.T) {
+		m := chi.NewRouter()
+		m.Use(binding.Injector(render.New()))
+		m.With(binding.Inject(func(inject.Injector) error { return errNotFound })).
+			Post(testRoute, binding.HandlerFunc(func() []byte { return nil }))
+
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader("")))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/bindingtest"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -177,6 +178,15 @@ func Test_Form(t *testing.T) {
 	}
 }
 
+func TestFormMalformedKey(t *testing.T) {
+	for _, key := range []string{"]", "rating[0"} {
+		_, resp := bindingtest.Run[BlogPost](t, binding.Form, bindingtest.Post(testRoute).Form(key, "1"))
+		if bindingtest.AssertCode(t, resp, http.StatusBadRequest) {
+			assert.Contains(t, bindingtest.DecodeStatus(t, resp).Message, "malformed form values")
+		}
+	}
+}
+
 func performFormTest(t *testing.T, binder binderFunc, testCase formTestCase) {
 	m := chi.NewRouter()
 	m.Use(middleware.Logger)
@@ -256,3 +266,12 @@ func performFormTest(t *testing.T, binder binderFunc, testCase formTestCase) {
 		assert.EqualValues(t, testCase.expectedStatusCode, resp.StatusCode)
 	}
 }
+
+func BenchmarkForm(b *testing.B) {
+	payload := `title=Glorious+Post+Title&id=1&author.name=Matt+Holt&rating=4&rating=3&rating=5`
+	benchmarkBinder(b, binding.Form, func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader(payload))
+		req.Header.Set("Content-Type", formContentType)
+		return req
+	})
+}
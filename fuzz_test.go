@@ -0,0 +1,113 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/bindingtest"
+	"go.wandrs.dev/binding/internal/testmodels"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fuzzModels are the models every fuzzed request is bound to.
+var fuzzModels = []func(t *testing.T, binder bindingtest.Binder, req *bindingtest.Request) *http.Response{
+	func(t *testing.T, binder bindingtest.Binder, req *bindingtest.Request) *http.Response {
+		_, resp := bindingtest.Run[BlogPost](t, binder, req)
+		return resp
+	},
+	func(t *testing.T, binder bindingtest.Binder, req *bindingtest.Request) *http.Response {
+		_, resp := bindingtest.Run[Group](t, binder, req)
+		return resp
+	},
+	func(t *testing.T, binder bindingtest.Binder, req *bindingtest.Request) *http.Response {
+		_, resp := bindingtest.Run[EmbedPerson](t, binder, req)
+		return resp
+	},
+	func(t *testing.T, binder bindingtest.Binder, req *bindingtest.Request) *http.Response {
+		_, resp := bindingtest.Run[testmodels.Person](t, binder, req) // generated binders
+		return resp
+	},
+}
+
+// checkFuzzResponse fails unless resp binds the model or reports the bad input
+// as a 4xx metav1.Status.
+func checkFuzzResponse(t *testing.T, resp *http.Response) {
+	t.Helper()
+	if resp.StatusCode == http.StatusOK {
+		return
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 400 || resp.StatusCode >= 500 {
+		t.Fatalf("status code %d: %s", resp.StatusCode, body)
+	}
+	var status metav1.Status
+	if err := json.Unmarshal(body, &status); err != nil || status.Code != int32(resp.StatusCode) {
+		t.Fatalf("body is not a metav1.Status with code %d: %s", resp.StatusCode, body)
+	}
+}
+
+func FuzzForm(f *testing.F) {
+	for _, seed := range []string{
+		"title=Glorious+Post+Title&id=1&author.name=Matt+Holt&rating=3&rating=5",
+		"title=x&rating[0]=1&rating[2]=3",
+		"rating[99999999]=1",
+		"rating[-1]=1",
+		"author.name=x&coauthor.name=y",
+		"people[0].name=x&people[1].name=y",
+		"title=\xff\xfe&name=%ff",
+		strings.Repeat("a.", 1000) + "name=x",
+		strings.Repeat("people[0].", 100) + "name=x",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, body string) {
+		for _, run := range fuzzModels {
+			req := bindingtest.Post(testRoute).Body([]byte(body)).ContentType(formContentType)
+			checkFuzzResponse(t, run(t, binding.Form, req))
+		}
+	})
+}
+
+func FuzzMultipartForm(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"title", "Glorious Post Title"},
+		{"rating[99999999]", "1"},
+		{"rating", "\xff"},
+		{"\xff", "x"},
+		{strings.Repeat("author.", 1000) + "name", "x"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+	f.Fuzz(func(t *testing.T, key, value string) {
+		for _, run := range fuzzModels {
+			req := bindingtest.Post(testRoute).Form("id", "1").Form(key, value).Multipart()
+			checkFuzzResponse(t, run(t, binding.MultipartForm, req))
+		}
+	})
+}
+
+func FuzzJSON(f *testing.F) {
+	for _, seed := range []string{
+		`{"title":"Glorious Post Title","id":1,"author":{"name":"Matt Holt"},"ratings":[3,5]}`,
+		`{"name":"x","people":[{"name":"y"}]}`,
+		`{"ratings":[1e400]}`,
+		`{"title":"\ud800"}`,
+		"{\"title\":\"\xff\"}",
+		strings.Repeat("[", 20000),
+		strings.Repeat(`{"author":`, 20000),
+		`null`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, body string) {
+		for _, run := range fuzzModels {
+			req := bindingtest.Post(testRoute).JSON(body)
+			checkFuzzResponse(t, run(t, binding.JSON, req))
+		}
+	})
+}
@@ -0,0 +1,187 @@
+// Package gen holds the helpers used by the code that cmd/bindinggen generates.
+// They mirror how go-playground/form decodes values and how go-playground/validator
+// reports errors, so that generated binders fail the same way as the reflection
+// based ones. Nothing in this package is meant to be called by hand.
+package gen
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/form/v4"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Values returns the values of key, with those of the indexed keys key[0],
+// key[1] and so on put at their index like form.Decoder does, so that key[0]
+// replaces the first value of key. Indexes beyond form's default maximum array
+// size are ignored.
+func Values(values url.Values, key string) []string {
+	vals := append([]string(nil), values[key]...)
+	prefix := key + "["
+	for k, v := range values {
+		if len(v) == 0 || !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, "]") {
+			continue
+		}
+		i, err := strconv.ParseUint(k[len(prefix):len(k)-1], 10, 0)
+		if err != nil || i >= maxArraySize {
+			continue
+		}
+		for uint64(len(vals)) <= i {
+			vals = append(vals, "")
+		}
+		vals[i] = v[0]
+	}
+	return vals
+}
+
+// maxArraySize is the default maximum array size of form.Decoder.
+const maxArraySize = 10000
+
+// HasPrefix reports whether any key of values starts with prefix, which tells
+// whether a nested struct behind a pointer has to be allocated.
+func HasPrefix(values url.Values, prefix string) bool {
+	for key := range values {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Int parses s like form.Decoder does, recording an error for key in errs.
+// Empty values are skipped.
+func Int(s string, bits int, typ, key string, errs form.DecodeErrors) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s, 10, bits)
+	if err != nil {
+		errs[key] = fmt.Errorf("Invalid Integer Value '%s' Type '%s' Namespace '%s'", s, typ, key)
+		return 0, false
+	}
+	return v, true
+}
+
+// Uint parses s like form.Decoder does, recording an error for key in errs.
+// Empty values are skipped.
+func Uint(s string, bits int, typ, key string, errs form.DecodeErrors) (uint64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		errs[key] = fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%s' Namespace '%s'", s, typ, key)
+		return 0, false
+	}
+	return v, true
+}
+
+// Float parses s like form.Decoder does, recording an error for key in errs.
+// Empty values are skipped.
+func Float(s string, bits int, typ, key string, errs form.DecodeErrors) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, bits)
+	if err != nil {
+		errs[key] = fmt.Errorf("Invalid Float Value '%s' Type '%s' Namespace '%s'", s, typ, key)
+		return 0, false
+	}
+	return v, true
+}
+
+// Bool parses s like form.Decoder does, which also accepts on, yes and ok,
+// recording an error for key in errs.
+func Bool(s string, typ, key string, errs form.DecodeErrors) (bool, bool) {
+	switch s {
+	case "1", "t", "T", "true", "TRUE", "True", "on", "yes", "ok":
+		return true, true
+	case "", "0", "f", "F", "false", "FALSE", "False", "off", "no":
+		return false, true
+	}
+	errs[key] = fmt.Errorf("Invalid Boolean Value '%s' Type '%s' Namespace '%s'", s, typ, key)
+	return false, false
+}
+
+// FieldError is a validator.FieldError reported by a generated validator.
+type FieldError struct {
+	tag   string
+	param string
+	ns    string
+	field string
+	value interface{}
+}
+
+var _ validator.FieldError = (*FieldError)(nil)
+
+// Tag returns the validation tag that failed.
+func (fe *FieldError) Tag() string { return fe.tag }
+
+// ActualTag returns the validation tag that failed, generated validators do not
+// resolve aliases.
+func (fe *FieldError) ActualTag() string { return fe.tag }
+
+// Namespace returns the namespace of the field, like Person.Name.
+func (fe *FieldError) Namespace() string { return fe.ns }
+
+// StructNamespace returns the namespace of the field with struct field names.
+func (fe *FieldError) StructNamespace() string { return fe.ns }
+
+// Field returns the name of the field.
+func (fe *FieldError) Field() string { return fe.field }
+
+// StructField returns the struct field name of the field.
+func (fe *FieldError) StructField() string { return fe.field }
+
+// Value returns the value of the field.
+func (fe *FieldError) Value() interface{} { return fe.value }
+
+// Param returns the parameter of the tag, like 3 for min=3.
+func (fe *FieldError) Param() string { return fe.param }
+
+// Kind returns the kind of the value of the field.
+func (fe *FieldError) Kind() reflect.Kind {
+	if fe.value == nil {
+		return reflect.Invalid
+	}
+	return reflect.TypeOf(fe.value).Kind()
+}
+
+// Type returns the type of the value of the field.
+func (fe *FieldError) Type() reflect.Type { return reflect.TypeOf(fe.value) }
+
+// Translate returns the error message, generated validators have no translations.
+func (fe *FieldError) Translate(ut.Translator) string { return fe.Error() }
+
+// Error returns the error message in the format of go-playground/validator.
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' tag", fe.ns, fe.field, fe.tag)
+}
+
+// Fail appends the failure of tag=param on the field name with value v in
+// namespace ns to errs.
+func Fail(errs validator.ValidationErrors, ns, name, tag, param string, v interface{}) validator.ValidationErrors {
+	return append(errs, &FieldError{tag: tag, param: param, ns: ns + "." + name, field: name, value: v})
+}
+
+// Field returns the index of the name matching the JSON object key, preferring
+// an exact match over a case-insensitive one like encoding/json, or -1 if no
+// name matches.
+func Field(key string, names ...string) int {
+	for i, name := range names {
+		if name == key {
+			return i
+		}
+	}
+	for i, name := range names {
+		if strings.EqualFold(name, key) {
+			return i
+		}
+	}
+	return -1
+}
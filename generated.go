@@ -0,0 +1,145 @@
+package binding
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/form/v4"
+	"github.com/go-playground/validator/v10"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Generated holds the reflection free functions that cmd/bindinggen generates
+// for a model type T. Once registered, the Bind family uses them instead of
+// form.Decoder, the JSON decoder and Validate.Struct for T. A nil function
+// keeps the reflection based default, which bindinggen leaves in place for
+// fields and validate tags it can not generate code for.
+type Generated[T any] struct {
+	// DecodeForm decodes values into obj using the form tags, naming the keys
+	// of nested structs after prefix and recording failures in errs.
+	DecodeForm func(obj *T, values url.Values, prefix string, errs form.DecodeErrors)
+	// DecodeQuery is like DecodeForm but uses the json tags.
+	DecodeQuery func(obj *T, values url.Values, prefix string, errs form.DecodeErrors)
+	// DecodeJSON decodes the JSON value read by iter into obj.
+	DecodeJSON func(obj *T, iter *jsoniter.Iterator)
+	// Validate appends the validate tag violations of obj in namespace ns to errs.
+	Validate func(obj *T, ns string, errs validator.ValidationErrors) validator.ValidationErrors
+}
+
+// generated is Generated with the type parameter erased.
+type generated struct {
+	decodeForm  func(obj interface{}, values url.Values) error
+	decodeQuery func(obj interface{}, values url.Values) error
+	decodeJSON  func(obj interface{}, r io.Reader) error
+	validate    func(obj interface{}) error
+}
+
+var generatedBinders = map[reflect.Type]*generated{}
+
+// RegisterGenerated registers the generated functions of model type T. It is
+// called from the init function of the code generated by bindinggen and is not
+// safe to call while serving requests.
+func RegisterGenerated[T any](g Generated[T]) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	gen := &generated{}
+	decodeValues := func(decode func(obj *T, values url.Values, prefix string, errs form.DecodeErrors)) func(obj interface{}, values url.Values) error {
+		return func(obj interface{}, values url.Values) error {
+			errs := form.DecodeErrors{}
+			decode(obj.(*T), values, "", errs)
+			if len(errs) > 0 {
+				return errs
+			}
+			return nil
+		}
+	}
+	if g.DecodeForm != nil {
+		gen.decodeForm = decodeValues(g.DecodeForm)
+	}
+	if g.DecodeQuery != nil {
+		gen.decodeQuery = decodeValues(g.DecodeQuery)
+	}
+	if g.DecodeJSON != nil {
+		gen.decodeJSON = func(obj interface{}, r io.Reader) error {
+			iter := jsoniter.Parse(json, r, 512)
+			if iter.WhatIsNext() == jsoniter.InvalidValue && iter.Error == io.EOF {
+				return io.EOF // empty body
+			}
+			g.DecodeJSON(obj.(*T), iter)
+			if iter.Error != nil && iter.Error != io.EOF {
+				return iter.Error
+			}
+			return nil
+		}
+	}
+	if g.Validate != nil {
+		gen.validate = func(obj interface{}) error {
+			if errs := g.Validate(obj.(*T), typ.Name(), nil); len(errs) > 0 {
+				return errs
+			}
+			return nil
+		}
+	}
+	generatedBinders[typ] = gen
+}
+
+// decodeForm decodes values into the model pointed to by obj using the form tags.
+func (plan *bindingPlan) decodeForm(obj reflect.Value, values url.Values) error {
+	if err := plan.limits.checkValues(values); err != nil {
+		return err
+	}
+	if gen := generatedBinders[plan.typ]; gen != nil && gen.decodeForm != nil {
+		return gen.decodeForm(obj.Interface(), values)
+	}
+	return decodeValues(plan.form, obj, values)
+}
+
+// decodeQuery decodes values into the model pointed to by obj using the json tags.
+func (plan *bindingPlan) decodeQuery(obj reflect.Value, values url.Values) error {
+	if err := plan.limits.checkValues(values); err != nil {
+		return err
+	}
+	if gen := generatedBinders[plan.typ]; gen != nil && gen.decodeQuery != nil {
+		return gen.decodeQuery(obj.Interface(), values)
+	}
+	return decodeValues(plan.query, obj, values)
+}
+
+// decodeValues decodes values with d. go-playground/form panics on malformed
+// keys like "]" or "a[b", so those panics are returned as errors to be written
+// as 400 Bad Request. Any other panic is a bug and is not recovered.
+func decodeValues(d *form.Decoder, obj reflect.Value, values url.Values) (err error) {
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			if msg, ok := rvr.(string); ok && strings.HasPrefix(msg, malformedKeyPanic) {
+				err = fmt.Errorf("malformed form values: %s", msg)
+				return
+			}
+			panic(rvr)
+		}
+	}()
+	return d.Decode(obj.Interface(), values)
+}
+
+// malformedKeyPanic starts the messages go-playground/form panics with when a
+// key has a missing bracket.
+const malformedKeyPanic = "Invalid formatting for key "
+
+// decodeJSON decodes the JSON value read from r into the model pointed to by obj.
+// It returns io.EOF if r is empty.
+func (plan *bindingPlan) decodeJSON(obj reflect.Value, r io.Reader) error {
+	if gen := generatedBinders[plan.typ]; gen != nil && gen.decodeJSON != nil {
+		return gen.decodeJSON(obj.Interface(), r)
+	}
+	return json.NewDecoder(r).Decode(obj.Interface())
+}
+
+// validate checks the validate tags of the model pointed to by obj.
+func (plan *bindingPlan) validate(obj reflect.Value) error {
+	if gen := generatedBinders[plan.typ]; gen != nil && gen.validate != nil {
+		return gen.validate(obj.Interface())
+	}
+	return check(obj)
+}
@@ -0,0 +1,305 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/bindingtest"
+	"go.wandrs.dev/binding/gen"
+	"go.wandrs.dev/binding/internal/testmodels"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerated(t *testing.T) {
+	johnny := "johnny"
+	tests := []struct {
+		name        string
+		binder      func(obj interface{}, ifacePtr ...interface{}) func(http.Handler) http.Handler
+		contentType string
+		body        string
+		code        int
+		want        testmodels.Person
+		causes      []string
+	}{
+		{
+			name:        "form",
+			binder:      binding.Form,
+			contentType: formContentType,
+			body:        "id=7&name=John&age=30&score=1.5&admin=on&role=admin&nick=johnny&tags=a&tags[1]=b&address.city=Berlin",
+			code:        http.StatusOK,
+			want: testmodels.Person{
+				Base:    testmodels.Base{ID: 7},
+				Name:    "John",
+				Age:     30,
+				Score:   1.5,
+				Admin:   true,
+				Role:    "admin",
+				Nick:    &johnny,
+				Tags:    []string{"a", "b"},
+				Address: &testmodels.Address{City: "Berlin"},
+			},
+		},
+		{
+			name:        "form decode errors",
+			binder:      binding.Form,
+			contentType: formContentType,
+			body:        "name=John&role=user&age=old&admin=maybe",
+			code:        http.StatusBadRequest,
+			causes:      []string{"admin", "age"},
+		},
+		{
+			name:        "form validation errors",
+			binder:      binding.Bind,
+			contentType: formContentType,
+			body:        "name=J&role=guest&tags=",
+			code:        http.StatusUnprocessableEntity,
+			causes:      []string{"Person.Name", "Person.Role", "Person.Tags[0]"},
+		},
+		{
+			name:        "json",
+			binder:      binding.JSON,
+			contentType: "application/json",
+			body:        `{"id":7,"Name":"John","age":30,"role":"user","nick":null,"tags":["a"],"address":{"city":"Berlin"},"previous":[{"city":"Paris","zip":"75001"}],"extra":{"k":"v"},"unknown":[1]}`,
+			code:        http.StatusOK,
+			want: testmodels.Person{
+				Base:     testmodels.Base{ID: 7},
+				Name:     "John",
+				Age:      30,
+				Role:     "user",
+				Tags:     []string{"a"},
+				Address:  &testmodels.Address{City: "Berlin"},
+				Previous: []testmodels.Address{{City: "Paris", Zip: "75001"}},
+				Extra:    map[string]string{"k": "v"},
+			},
+		},
+		{
+			name:        "json type error",
+			binder:      binding.Bind,
+			contentType: "application/json",
+			body:        `{"name":1}`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "json validation errors",
+			binder:      binding.JSON,
+			contentType: "application/json",
+			body:        `{"name":"John","role":"user","previous":[{"zip":"1"}]}`,
+			code:        http.StatusUnprocessableEntity,
+			causes:      []string{"Person.Previous[0].City", "Person.Previous[0].Zip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testmodels.Person
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.With(tt.binder(testmodels.Person{})).Post(testRoute, binding.HandlerFunc(func(p testmodels.Person) []byte {
+				got = p
+				return nil
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			require.Equal(t, tt.code, w.Code, w.Body.String())
+			if tt.code == http.StatusOK {
+				assert.Equal(t, tt.want, got)
+				return
+			}
+			if tt.causes == nil {
+				return
+			}
+			var status metav1.Status
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			require.NotNil(t, status.Details)
+			fields := make([]string, 0, len(status.Details.Causes))
+			for _, cause := range status.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			assert.ElementsMatch(t, tt.causes, fields)
+		})
+	}
+}
+
+type generatedOnly struct {
+	Value string
+}
+
+func init() {
+	binding.RegisterGenerated(binding.Generated[generatedOnly]{
+		DecodeJSON: func(x *generatedOnly, iter *jsoniter.Iterator) {
+			x.Value = "generated " + iter.ReadString()
+		},
+		Validate: func(x *generatedOnly, ns string, errs validator.ValidationErrors) validator.ValidationErrors {
+			if x.Value == "generated invalid" {
+				errs = gen.Fail(errs, ns, "Value", "custom", "", x.Value)
+			}
+			return errs
+		},
+	})
+}
+
+func TestRegisterGenerated(t *testing.T) {
+	var got generatedOnly
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.With(binding.JSON(generatedOnly{})).Post(testRoute, binding.HandlerFunc(func(v generatedOnly) []byte {
+		got = v
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader(`"value"`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "generated value", got.Value)
+
+	req = httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader(`"invalid"`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var status metav1.Status
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Len(t, status.Details.Causes, 1)
+	assert.Equal(t, "generatedOnly.Value", status.Details.Causes[0].Field)
+}
+
+// reflectedPerson has the fields of testmodels.Person but no generated binders,
+// so that the Bind family decodes and validates it with reflection.
+type reflectedPerson testmodels.Person
+
+func TestGeneratedParity(t *testing.T) {
+	// causes returns the cause types by field, without the leading model name
+	// that differs between the two types.
+	causes := func(t *testing.T, resp *http.Response) map[string]metav1.CauseType {
+		t.Helper()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		status := bindingtest.DecodeStatus(t, resp)
+		if status.Details == nil {
+			return nil
+		}
+		fields := map[string]metav1.CauseType{}
+		for _, cause := range status.Details.Causes {
+			field := strings.TrimPrefix(strings.TrimPrefix(cause.Field, "Person."), "reflectedPerson.")
+			fields[field] = cause.Type
+		}
+		return fields
+	}
+
+	tests := []struct {
+		name   string
+		binder bindingtest.Binder
+		req    func() *bindingtest.Request
+		code   int
+	}{
+		{
+			name:   "form",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("id", "7").Form("name", "John").Form("age", "30").Form("score", "1.5").
+					Form("admin", "on").Form("role", "admin").Form("nick", "johnny").Form("address.city", "Berlin")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "embedded field",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("name", "John").Form("role", "user").Form("id", "7")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "embedded field by struct name",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("name", "John").Form("role", "user").Form("Base.id", "7")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "repeated and indexed keys",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("name", "John").Form("role", "user").Form("tags", "a").Form("tags[0]", "b")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "indexed keys",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("name", "John").Form("role", "user").Form("tags[0]", "a").Form("tags[1]", "b")
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "form decode errors",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("name", "John").Form("role", "user").Form("age", "old").Form("admin", "maybe").Form("address.city", "Berlin")
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:   "form validation errors",
+			binder: binding.Form,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).Form("name", "J").Form("role", "guest").Form("tags", "").Form("nick", "jo").Form("age", "200")
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "json",
+			binder: binding.JSON,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).JSON(`{"id":7,"Name":"John","age":30,"role":"user","nick":null,"tags":["a"],"address":{"city":"Berlin"},"previous":[{"city":"Paris","zip":"75001"}],"extra":{"k":"v"},"unknown":[1]}`)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "json validation errors",
+			binder: binding.JSON,
+			req: func() *bindingtest.Request {
+				return bindingtest.Post(testRoute).JSON(`{"name":"John","role":"user","tags":["a","b","c","d"],"address":{},"previous":[{"zip":"1"}]}`)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "query",
+			binder: binding.Bind,
+			req: func() *bindingtest.Request {
+				return bindingtest.Get(testRoute).Query("id", "7").Query("name", "John").Query("role", "user").Query("tags", "a", "b")
+			},
+			code: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generated, generatedResp := bindingtest.Run[testmodels.Person](t, tt.binder, tt.req())
+			reflected, reflectedResp := bindingtest.Run[reflectedPerson](t, tt.binder, tt.req())
+
+			require.Equal(t, tt.code, reflectedResp.StatusCode)
+			require.Equal(t, reflectedResp.StatusCode, generatedResp.StatusCode)
+			assert.Equal(t, testmodels.Person(reflected), generated)
+			assert.Equal(t, causes(t, reflectedResp), causes(t, generatedResp))
+		})
+	}
+}
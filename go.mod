@@ -5,8 +5,10 @@ go 1.19
 require (
 	github.com/go-chi/chi/v5 v5.0.3
 	github.com/go-playground/form/v4 v4.1.3
+	github.com/go-playground/universal-translator v0.17.0
 	github.com/go-playground/validator/v10 v10.6.1
 	github.com/json-iterator/go v1.1.12
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/stretchr/testify v1.8.0
 	github.com/unrolled/render v1.4.0
 	go.wandrs.dev/http v0.0.1
@@ -19,7 +21,6 @@ require (
 	github.com/fsnotify/fsnotify v1.5.4 // indirect
 	github.com/go-logr/logr v1.2.3 // indirect
 	github.com/go-playground/locales v0.13.0 // indirect
-	github.com/go-playground/universal-translator v0.17.0 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
 	github.com/google/gofuzz v1.2.0 // indirect
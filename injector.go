@@ -2,9 +2,12 @@ package binding
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"reflect"
+	"runtime/debug"
 	"sync"
 
 	httpw "go.wandrs.dev/http"
@@ -12,71 +15,112 @@ import (
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/unrolled/render"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 var pool = sync.Pool{
 	New: func() interface{} {
-		return inject.New()
+		return newScope()
 	},
 }
 
 type injectorKey struct{}
 
+// Injector creates the injector for a request and pre-injects the request, its
+// context and the ResponseWriters. A panic while serving the request is recovered,
+// logged with PanicLogger and written as a 500 metav1.Status.
+//
+// When used again in a sub router, it creates a child injector which falls back
+// to the injector of the parent router. Values mapped in the sub router, including
+// the ResponseWriter using r, shadow those of the parent only for that sub router.
 func Injector(r *render.Render) func(next http.Handler) http.Handler {
 	if r == nil {
 		panic("chi: render must not be nil")
 	}
 
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			// Check if a routing context already exists from a parent router.
-			injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
-			if injector != nil {
-				// in case middleware.Logger is used only for this sub router
-				if ww, ok := w.(middleware.WrapResponseWriter); ok {
-					injector.MapTo(ww, (*middleware.WrapResponseWriter)(nil))
-				}
-				// give a chance to override render.Render
-				injector.MapTo(httpw.NewResponseWriter(w, req, r), (*httpw.ResponseWriter)(nil))
+		return injecting(injectorTypes, func(w http.ResponseWriter, req *http.Request) {
+			// Check if an injector already exists from a parent router. Then this
+			// router gets a child injector, so its mappings shadow those of the
+			// parent without leaking to other routers of the parent.
+			parent, _ := req.Context().Value(injectorKey{}).(inject.Injector)
 
-				next.ServeHTTP(w, req)
-				return
-			}
-
-			injector = pool.Get().(inject.Injector)
-			injector.Reset()
+			sc := pool.Get().(*scope)
+			sc.reset(parent)
+			injector := sc.injector
 
 			// NOTE: req.WithContext() causes 2 allocations and context.WithValue() causes 1 allocation
 			ctx := context.WithValue(req.Context(), injectorKey{}, injector)
 			req = req.WithContext(ctx)
 
+			// the status code is needed by the OnFinish hooks
+			ww, ok := w.(middleware.WrapResponseWriter)
+			if !ok {
+				ww = middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+				w = ww
+			}
+
 			injector.MapTo(ctx, (*context.Context)(nil))
 			injector.Map(req)
 			injector.MapTo(w, (*http.ResponseWriter)(nil))
-			if ww, ok := w.(middleware.WrapResponseWriter); ok {
-				injector.MapTo(ww, (*middleware.WrapResponseWriter)(nil))
-			}
+			injector.MapTo(ww, (*middleware.WrapResponseWriter)(nil))
 			injector.MapTo(httpw.NewResponseWriter(w, req, r), (*httpw.ResponseWriter)(nil))
 
 			// Serve the request and once its done, put the request context back in the sync pool
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					sc.done(ww.Status(), sc.err)
+					pool.Put(sc)
+					return
+				}
+
+				// the injector may be left half way through a request, so it is not reused
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusInternalServerError
+				}
+				sc.done(status, fmt.Errorf("panic: %v", rvr))
+				if parent != nil {
+					panic(rvr) // recovered by the Injector of the parent router
+				}
+				recoverPanic(injector, req, rvr)
+			}()
 			next.ServeHTTP(w, req)
-			pool.Put(injector)
 		})
 	}
 }
 
-// Inject allows injecting new values for a given request
+// PanicLogger is called with the recovered value and the stack trace when a
+// handler served under the Injector middleware panics.
+var PanicLogger = func(req *http.Request, rvr interface{}, stack []byte) {
+	log.Printf("panic serving %s %s: %v\n%s", req.Method, req.URL, rvr, stack)
+}
+
+func recoverPanic(injector inject.Injector, req *http.Request, rvr interface{}) {
+	if rvr == http.ErrAbortHandler {
+		panic(rvr) // let net/http abort the response
+	}
+	PanicLogger(req, rvr, debug.Stack())
+
+	ww := ResponseWriter(injector)
+	if !ww.Written() {
+		ww.APIError(apierrors.NewInternalError(errors.New("this request caused a panic, look in the logs for details")))
+	}
+}
+
+// Inject allows injecting new values for a given request.
+// The values fn maps are not closed when the request finishes, see CloseOnFinish.
 func Inject(fn func(inject.Injector) error) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		return injecting(nil, func(w http.ResponseWriter, req *http.Request) {
 			injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
 			}
 
 			if err := fn(injector); err != nil {
-				ww := ResponseWriter(injector)
-				ww.APIError(err)
+				writeError(injector, err)
 				return
 			}
 			next.ServeHTTP(w, req)
@@ -86,8 +130,9 @@ func Inject(fn func(inject.Injector) error) func(next http.Handler) http.Handler
 
 // Maps the interface{} value based on its immediate type from reflect.TypeOf.
 func Map(val interface{}) func(next http.Handler) http.Handler {
+	types := []reflect.Type{reflect.TypeOf(val)}
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		return injecting(types, func(w http.ResponseWriter, req *http.Request) {
 			injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
@@ -103,8 +148,9 @@ func Map(val interface{}) func(next http.Handler) http.Handler {
 // This is really only useful for mapping a value as an interface, as interfaces
 // cannot at this time be referenced directly without a pointer.
 func MapTo(val interface{}, ifacePtr interface{}) func(next http.Handler) http.Handler {
+	types := []reflect.Type{inject.InterfaceOf(ifacePtr)}
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		return injecting(types, func(w http.ResponseWriter, req *http.Request) {
 			injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
@@ -120,8 +166,9 @@ func MapTo(val interface{}, ifacePtr interface{}) func(next http.Handler) http.H
 // This makes it possible to directly map type arguments not possible to instantiate
 // with reflect like unidirectional channels.
 func Set(typ reflect.Type, val reflect.Value) func(next http.Handler) http.Handler {
+	types := []reflect.Type{typ}
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		return injecting(types, func(w http.ResponseWriter, req *http.Request) {
 			injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
 			if injector == nil {
 				panic("chi: register Injector middleware")
@@ -133,7 +180,25 @@ func Set(typ reflect.Type, val reflect.Value) func(next http.Handler) http.Handl
 	}
 }
 
-var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var (
+	errorType              = reflect.TypeOf((*error)(nil)).Elem()
+	contextType            = reflect.TypeOf((*context.Context)(nil)).Elem()
+	responseWriterType     = reflect.TypeOf((*httpw.ResponseWriter)(nil)).Elem()
+	httpResponseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	wrapResponseWriterType = reflect.TypeOf((*middleware.WrapResponseWriter)(nil)).Elem()
+	requestType            = reflect.TypeOf((*http.Request)(nil))
+)
+
+// injectorTypes are the types pre-injected by the Injector middleware, led by
+// injectorKey which marks the middleware itself.
+var injectorTypes = []reflect.Type{
+	reflect.TypeOf(injectorKey{}),
+	contextType,
+	requestType,
+	httpResponseWriterType,
+	wrapResponseWriterType,
+	responseWriterType,
+}
 
 // HandlerFunc converts a regular function into a net/http handler.
 //
@@ -150,27 +215,148 @@ var errorType = reflect.TypeOf((*error)(nil)).Elem()
 //   If an error is returned, then converted to metav1.Status and written to http.ResponseWriter as a JSON object.
 //   Otherwise, []byte is written directly and some_value is converted to JSON and written to http.ResponseWriter
 //
+// Errors that are not API errors from k8s.io/apimachinery/pkg/api/errors are written as 500, unless
+// they are mapped with RegisterError or RegisterErrorType or wrap a *apierrors.StatusError.
+//
+// JSON is the default, but some_value and metav1.Status are written in any media
+// type registered with RegisterEncoder that the Accept header of the request prefers,
+// like YAML. If none is acceptable, some_value is replaced with a 406 metav1.Status.
+// A some_value that implements TableConvertor, or has a convertor registered with
+// RegisterTableConvertor, is written as a metav1.Table if the client asks for one.
+//
+// some_value is written with http.StatusOK, unless it is a Response or implements
+// StatusCoder to choose the status code and Headerer to add response headers.
+// Large results can be streamed instead of being buffered in full:
+//   io.Reader                 # copied to http.ResponseWriter, Content-Type is detected unless set
+//   <-chan T                  # each T is written as it is received, until the channel is closed
+//   func(yield func(T) bool)  # each T is written as it is yielded
+// Channels and iterators are written as a JSON array, or as newline delimited JSON
+// if the request accepts application/x-ndjson, flushing after every element.
+//   <-chan Event              # written as Server-Sent Events with heartbeats
+//   watch.Interface           # written as metav1.WatchEvent frames like kube-apiserver, also <-chan watch.Event
+// Watches honor the allowWatchBookmarks and timeoutSeconds query parameters.
+// Streaming stops when the request context is done.
+//
 // Each of these functions can take any injected values as argument including the following pre-injected ones:
 //  - r *http.Request
 //  - w httpw.ResponseWriter # the recommended ResponseWriter as it has helper methods like macaron.Context
 //  - w http.ResponseWriter  # net/http ResponseWriter
-//  - w middleware.WrapResponseWriter # go-chi's ResponseWriter wrapper. Use(middleware.Logger) to inject this.
+//  - w middleware.WrapResponseWriter # go-chi's ResponseWriter wrapper
+//  - id LastEventID # the Last-Event-ID header of a reconnecting Server-Sent Events client
+// Values registered with Provide are constructed only when a function asks for them.
+//
+// A missing argument or a function that returns nothing without writing panics,
+// which the Injector middleware recovers into a 500 metav1.Status.
 func HandlerFunc(fn interface{}) http.HandlerFunc {
-	firstReturnIsErr := false
+	plan := newHandlerPlan(fn)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
+		if injector == nil {
+			// Verify asks for the plan without serving the request
+			if probe, ok := req.Context().Value(planKey{}).(**handlerPlan); ok {
+				*probe = plan
+				return
+			}
+			panic("chi: register Injector middleware")
+		}
+		injector.Set(contextType, reflect.ValueOf(req.Context())) // make sure we have the latest Context
+		if plan.lastEventID {
+			injector.Set(lastEventIDType, reflect.ValueOf(LastEventID(req.Header.Get("Last-Event-ID"))))
+		}
+
+		in := make([]reflect.Value, len(plan.in))
+		for i, t := range plan.in {
+			val, err := resolve(injector, t)
+			if err != nil {
+				writeError(injector, err)
+				return
+			}
+			if !val.IsValid() {
+				panic(fmt.Sprintf("failed to invoke %s, reason: value not found for type %v", plan.typ, t))
+			}
+			in[i] = val
+		}
+		results := plan.fn.Call(in)
+
+		ww := ResponseWriter(injector)
+		switch plan.kind {
+		case returnsNothing:
+			if !ww.Written() {
+				panic(fmt.Sprintf("fn %s must write to ResponseWriter, since it returns nothing", plan.typ))
+			}
+			return // nothing returned, assuming function directly wrote to http.ResponseWriter
+		case returnsError:
+			err, _ := results[0].Interface().(error)
+			if ww.Written() {
+				return
+			}
+
+			writeError(injector, err)
+			return
+		case returnsValueAndError:
+			err, _ := results[1].Interface().(error)
+			// WARNING: https://stackoverflow.com/a/46275411/244009
+			if err != nil && !results[1].IsNil() /*for error wrapper interfaces*/ {
+				writeError(injector, err)
+				return
+			}
+		}
+
+		writeResult(w, req, injector, results[0])
+	}
+}
+
+// returnKind classifies the return values of a HandlerFunc.
+type returnKind int
+
+const (
+	returnsNothing       returnKind = iota // func(...)
+	returnsError                           // func(...) error
+	returnsValue                           // func(...) some_value
+	returnsValueAndError                   // func(...) (some_value, error)
+)
+
+// handlerPlan is everything HandlerFunc needs to know about fn, resolved once
+// when the handler is created instead of on every request.
+type handlerPlan struct {
+	fn   reflect.Value
+	typ  reflect.Type
+	in   []reflect.Type
+	kind returnKind
+
+	lastEventID bool // fn takes the Last-Event-ID header as argument
+}
 
+func newHandlerPlan(fn interface{}) *handlerPlan {
 	typ := reflect.TypeOf(fn)
 	if typ.Kind() != reflect.Func {
 		panic(fmt.Sprintf("fn %s must be a function, found %s", typ, typ.Kind()))
 	}
+
+	plan := &handlerPlan{
+		fn:  reflect.ValueOf(fn),
+		typ: typ,
+		in:  make([]reflect.Type, typ.NumIn()),
+	}
+	for i := range plan.in {
+		plan.in[i] = typ.In(i)
+		if plan.in[i] == lastEventIDType {
+			plan.lastEventID = true
+		}
+	}
+
 	switch typ.NumOut() {
 	case 0:
-		// nothing more to check
+		plan.kind = returnsNothing
 	case 1:
 		etyp := typ.Out(0)
 		if etyp.Implements(errorType) {
-			firstReturnIsErr = true
+			plan.kind = returnsError
 		} else if reflect.New(etyp).Type().Implements(errorType) {
 			panic(fmt.Sprintf("fn %s return type should be *%s to be considered an error", typ, etyp.Name()))
+		} else {
+			plan.kind = returnsValue
 		}
 	case 2:
 		etyp := typ.Out(1)
@@ -184,72 +370,40 @@ func HandlerFunc(fn interface{}) http.HandlerFunc {
 		if vtyp.Implements(errorType) {
 			panic(fmt.Sprintf("fn %s 1st return value must not an error", typ))
 		}
+		plan.kind = returnsValueAndError
 	default:
 		panic(fmt.Sprintf("fn %s has %d return values, at most 2 are allowed", typ, typ.NumOut()))
 	}
+	return plan
+}
 
-	return func(w http.ResponseWriter, req *http.Request) {
-		injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
-		if injector == nil {
-			panic("chi: register Injector middleware")
-		}
-		injector.MapTo(req.Context(), (*context.Context)(nil)) // make sure we have the latest Context
-
-		results, err := injector.Invoke(fn)
-		if err != nil {
-			panic(fmt.Sprintf("failed to invoke %s, reason: %v", typ.String(), err))
-		}
-
-		ww := ResponseWriter(injector)
-		switch len(results) {
-		case 0:
-			if !ww.Written() {
-				panic(fmt.Sprintf("fn %s must write to ResponseWriter, since it returns nothing", typ))
-			}
-			return // nothing returned, assuming function directly wrote to http.ResponseWriter
-		case 1:
-			if firstReturnIsErr {
-				err, _ := results[0].Interface().(error)
-				if ww.Written() {
-					return
-				}
-
-				ww.APIError(err)
-				return
-			}
-
-			v := results[0]
-			if isByteSlice(v) {
-				_, _ = w.Write(v.Bytes())
-			} else {
-				ww.JSON(http.StatusOK, v.Interface())
-			}
-			return
-		case 2:
-			err, _ := results[1].Interface().(error)
-			// WARNING: https://stackoverflow.com/a/46275411/244009
-			if err != nil && !reflect.ValueOf(err).IsNil() /*for error wrapper interfaces*/ {
-				ww.APIError(err)
-				return
-			}
+func ResponseWriter(injector inject.Injector) httpw.ResponseWriter {
+	return injector.GetVal(responseWriterType).Interface().(httpw.ResponseWriter)
+}
 
-			v := results[0]
-			if isByteSlice(v) {
-				_, _ = w.Write(v.Bytes())
-			} else {
-				ww.JSON(http.StatusOK, v.Interface())
-			}
-			return
-		default:
-			panic(fmt.Sprintf("received %d return values, can only handle upto 2 return values", len(results)))
+// writeError converts err to metav1.Status and writes it to http.ResponseWriter
+// in the media type negotiated from the Accept header. Errors registered with
+// RegisterError or RegisterErrorType are mapped to their API error first. The
+// original error is kept for the OnFinish hooks of the request.
+func writeError(injector inject.Injector, err error) {
+	if !isNil(err) {
+		// the hooks of the parent routers see the error as well
+		for sc := scopeOf(injector); sc != nil; sc = sc.parent {
+			sc.err = err
 		}
 	}
+	writeStatus(injector, mapError(err))
 }
 
-func ResponseWriter(injector inject.Injector) httpw.ResponseWriter {
-	return injector.GetVal(inject.InterfaceOf((*httpw.ResponseWriter)(nil))).Interface().(httpw.ResponseWriter)
-}
-
-func isByteSlice(val reflect.Value) bool {
-	return val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8
+// isNil reports whether v is nil, including a nil pointer wrapped in an interface.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return val.IsNil()
+	}
+	return false
 }
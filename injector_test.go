@@ -14,7 +14,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
 	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var returnErr = errors.New("err")
@@ -704,3 +706,248 @@ func TestHandlerFunc(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkHandlerFunc(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		handler http.Handler
+	}{
+		{
+			name: "http.HandlerFunc",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte("handler"))
+			}),
+		},
+		{
+			name: "binding.HandlerFunc",
+			handler: binding.HandlerFunc(func(w httpw.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte("handler"))
+			}),
+		},
+		{
+			name:    "binding.HandlerFunc returns []byte",
+			handler: binding.HandlerFunc(h2_returns_byte_array),
+		},
+		{
+			name:    "binding.HandlerFunc returns struct",
+			handler: binding.HandlerFunc(h2_returns_struct),
+		},
+		{
+			name:    "binding.HandlerFunc returns error",
+			handler: binding.HandlerFunc(h2_returns_struct_err),
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.Get("/", bm.handler.ServeHTTP)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+		})
+	}
+}
+
+func TestInjectorRecovery(t *testing.T) {
+	defer func(logger func(*http.Request, interface{}, []byte)) {
+		binding.PanicLogger = logger
+	}(binding.PanicLogger)
+
+	tests := []struct {
+		name    string
+		handler interface{}
+		want    int
+	}{
+		{
+			name:    "handler panics",
+			handler: func() string { panic("boom") },
+			want:    http.StatusInternalServerError,
+		},
+		{
+			name:    "missing argument",
+			handler: func(p Person) Person { return p },
+			want:    http.StatusInternalServerError,
+		},
+		{
+			name:    "nothing written",
+			handler: func(w http.ResponseWriter) {},
+			want:    http.StatusInternalServerError,
+		},
+		{
+			name:    "no panic",
+			handler: h1_returns_struct,
+			want:    http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged interface{}
+			binding.PanicLogger = func(req *http.Request, rvr interface{}, stack []byte) {
+				logged = rvr
+			}
+
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.Get("/", binding.HandlerFunc(tt.handler))
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			resp := w.Result()
+			assert.Equal(t, tt.want, resp.StatusCode)
+			if tt.want == http.StatusInternalServerError {
+				assert.NotNil(t, logged)
+
+				var status metav1.Status
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+				assert.Equal(t, metav1.StatusReasonInternalError, status.Reason)
+			} else {
+				assert.Nil(t, logged)
+			}
+		})
+	}
+}
+
+func TestInjectorSubRouter(t *testing.T) {
+	var seen []string
+	afterSubRouter := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req)
+			binding.HandlerFunc(func(p Person) []byte {
+				seen = append(seen, p.Name)
+				return nil
+			}).ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Use(binding.Map(Person{Name: "root"}))
+	m.Use(binding.Provide(func(p Person) Post { return Post{Title: p.Name} }))
+	m.Use(afterSubRouter)
+	m.Get("/", binding.HandlerFunc(func(p Person) string { return p.Name }))
+	m.Route("/shadow", func(r chi.Router) {
+		r.Use(binding.Injector(render.New(render.Options{IndentJSON: true})))
+		r.Use(binding.Map(Person{Name: "shadow"}))
+		r.Get("/", binding.HandlerFunc(func(p Person, post Post) Post { return post }))
+	})
+	m.Route("/inherit", func(r chi.Router) {
+		r.Use(binding.Injector(render.New()))
+		r.Get("/", binding.HandlerFunc(func(p Person) string { return p.Name }))
+	})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "/", want: `"root"`},
+		{url: "/shadow/", want: "{\n  \"title\": \"shadow\",\n  \"content\": \"\"\n}\n"},
+		{url: "/inherit/", want: `"root"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			seen = nil
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, w.Body.String())
+			assert.Equal(t, []string{"root"}, seen)
+		})
+	}
+}
+
+type created struct {
+	Name string `json:"name"`
+}
+
+func (created) StatusCode() int {
+	return http.StatusCreated
+}
+
+func (c created) Header() http.Header {
+	return http.Header{"Location": {"/people/" + c.Name}}
+}
+
+func TestHandlerFuncResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		code    int
+		header  http.Header
+		body    string
+	}{
+		{
+			name: "Response",
+			handler: func() (binding.Response[Person], error) {
+				return binding.Response[Person]{
+					Status: http.StatusAccepted,
+					Header: http.Header{"Etag": {`"v1"`}},
+					Body:   Person{Name: "John"},
+				}, nil
+			},
+			code:   http.StatusAccepted,
+			header: http.Header{"Etag": {`"v1"`}},
+			body:   toJSON(Person{Name: "John"}),
+		},
+		{
+			name: "Response pointer",
+			handler: func() *binding.Response[[]byte] {
+				return &binding.Response[[]byte]{Status: http.StatusCreated, Body: []byte("handler")}
+			},
+			code: http.StatusCreated,
+			body: "handler",
+		},
+		{
+			name: "Response without status",
+			handler: func() binding.Response[string] {
+				return binding.Response[string]{Body: "handler"}
+			},
+			code: http.StatusOK,
+			body: toJSON("handler"),
+		},
+		{
+			name: "Response no content",
+			handler: func() binding.Response[*Person] {
+				return binding.Response[*Person]{Status: http.StatusNoContent}
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "Response error",
+			handler: func() (binding.Response[Person], error) {
+				return binding.Response[Person]{Status: http.StatusCreated}, returnErr
+			},
+			code: http.StatusInternalServerError,
+			body: toJSON(httpw.ErrorToAPIStatus(returnErr)),
+		},
+		{
+			name:    "StatusCoder and Headerer",
+			handler: func() (created, error) { return created{Name: "john"}, nil },
+			code:    http.StatusCreated,
+			header:  http.Header{"Location": {"/people/john"}},
+			body:    toJSON(created{Name: "john"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.Get("/", binding.HandlerFunc(tt.handler))
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+			for k := range tt.header {
+				assert.Equal(t, tt.header.Get(k), w.Header().Get(k))
+			}
+		})
+	}
+}
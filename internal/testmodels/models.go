@@ -0,0 +1,35 @@
+// Package testmodels holds the models the tests bind with the code generated by
+// cmd/bindinggen.
+package testmodels
+
+//go:generate go run ../../cmd/bindinggen -type Person
+
+// Role is the role of a Person.
+type Role string
+
+// Base holds the fields shared by all models.
+type Base struct {
+	ID int64 `form:"id" json:"id"`
+}
+
+// Address is the address of a Person.
+type Address struct {
+	City string `form:"city" json:"city" validate:"required"`
+	Zip  string `form:"zip" json:"zip,omitempty" validate:"omitempty,len=5"`
+}
+
+// Person is a model with fields of every kind bindinggen supports.
+type Person struct {
+	Base
+	Name     string            `form:"name" json:"name" validate:"required,min=2,max=32"`
+	Age      int               `form:"age" json:"age" validate:"gte=0,lte=150"`
+	Score    float64           `form:"score" json:"score,omitempty"`
+	Admin    bool              `form:"admin" json:"admin,omitempty"`
+	Role     Role              `form:"role" json:"role" validate:"oneof=admin user"`
+	Nick     *string           `form:"nick" json:"nick,omitempty" validate:"omitempty,min=3"`
+	Tags     []string          `form:"tags" json:"tags,omitempty" validate:"max=3,dive,min=1"`
+	Lucky    []uint8           `form:"-" json:"-"`
+	Address  *Address          `form:"address" json:"address,omitempty"`
+	Previous []Address         `form:"-" json:"previous,omitempty" validate:"dive"`
+	Extra    map[string]string `form:"-" json:"extra,omitempty"`
+}
@@ -0,0 +1,243 @@
+// Code generated by "bindinggen -type Person"; DO NOT EDIT.
+
+package testmodels
+
+import (
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/go-playground/form/v4"
+	"github.com/go-playground/validator/v10"
+	jsoniter "github.com/json-iterator/go"
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/gen"
+)
+
+func init() {
+	binding.RegisterGenerated(binding.Generated[Person]{
+		DecodeForm: _Person_decodeForm,
+		DecodeJSON: _Person_decodeJSON,
+		Validate:   _Person_validate,
+	})
+}
+
+func _Person_decodeForm(x *Person, values url.Values, prefix string, errs form.DecodeErrors) {
+	_Base_decodeForm(&x.Base, values, prefix, errs)
+	_Base_decodeForm(&x.Base, values, prefix+"Base.", errs)
+	if vals := values[prefix+"name"]; len(vals) > 0 {
+		x.Name = vals[0]
+	}
+	if vals := values[prefix+"age"]; len(vals) > 0 {
+		if v, ok := gen.Int(vals[0], 0, "int", prefix+"age", errs); ok {
+			x.Age = int(v)
+		}
+	}
+	if vals := values[prefix+"score"]; len(vals) > 0 {
+		if v, ok := gen.Float(vals[0], 64, "float64", prefix+"score", errs); ok {
+			x.Score = v
+		}
+	}
+	if vals := values[prefix+"admin"]; len(vals) > 0 {
+		if v, ok := gen.Bool(vals[0], "bool", prefix+"admin", errs); ok {
+			x.Admin = v
+		}
+	}
+	if vals := values[prefix+"role"]; len(vals) > 0 {
+		x.Role = Role(vals[0])
+	}
+	if vals := values[prefix+"nick"]; len(vals) > 0 {
+		p := vals[0]
+		x.Nick = &p
+	}
+	if vals := gen.Values(values, prefix+"tags"); len(vals) > 0 {
+		s := make([]string, len(vals))
+		for i, val := range vals {
+			s[i] = val
+		}
+		x.Tags = s
+	}
+	if gen.HasPrefix(values, prefix+"address.") {
+		if x.Address == nil {
+			x.Address = new(Address)
+		}
+		_Address_decodeForm(x.Address, values, prefix+"address.", errs)
+	}
+}
+
+func _Person_decodeJSON(x *Person, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		return
+	}
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+		switch gen.Field(key, "id", "name", "age", "score", "admin", "role", "nick", "tags", "address", "previous", "extra") {
+		case 0:
+			if !iter.ReadNil() {
+				x.Base.ID = iter.ReadInt64()
+			}
+		case 1:
+			if !iter.ReadNil() {
+				x.Name = iter.ReadString()
+			}
+		case 2:
+			if !iter.ReadNil() {
+				x.Age = iter.ReadInt()
+			}
+		case 3:
+			if !iter.ReadNil() {
+				x.Score = iter.ReadFloat64()
+			}
+		case 4:
+			if !iter.ReadNil() {
+				x.Admin = iter.ReadBool()
+			}
+		case 5:
+			if !iter.ReadNil() {
+				x.Role = Role(iter.ReadString())
+			}
+		case 6:
+			if iter.ReadNil() {
+				x.Nick = nil
+			} else {
+				p := iter.ReadString()
+				x.Nick = &p
+			}
+		case 7:
+			if iter.ReadNil() {
+				x.Tags = nil
+			} else {
+				s := []string{}
+				iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+					var v string
+					if !iter.ReadNil() {
+						v = iter.ReadString()
+					}
+					s = append(s, v)
+					return true
+				})
+				x.Tags = s
+			}
+		case 8:
+			if iter.ReadNil() {
+				x.Address = nil
+			} else {
+				if x.Address == nil {
+					x.Address = new(Address)
+				}
+				_Address_decodeJSON(x.Address, iter)
+			}
+		case 9:
+			if iter.ReadNil() {
+				x.Previous = nil
+			} else {
+				s := []Address{}
+				iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+					var v Address
+					_Address_decodeJSON(&v, iter)
+					s = append(s, v)
+					return true
+				})
+				x.Previous = s
+			}
+		case 10:
+			iter.ReadVal(&x.Extra)
+		default:
+			iter.Skip()
+		}
+		return true
+	})
+}
+
+func _Person_validate(x *Person, ns string, errs validator.ValidationErrors) validator.ValidationErrors {
+	errs = _Base_validate(&x.Base, ns+".Base", errs)
+	if x.Name == "" {
+		errs = gen.Fail(errs, ns, "Name", "required", "", x.Name)
+	} else if utf8.RuneCountInString(x.Name) < 2 {
+		errs = gen.Fail(errs, ns, "Name", "min", "2", x.Name)
+	} else if utf8.RuneCountInString(x.Name) > 32 {
+		errs = gen.Fail(errs, ns, "Name", "max", "32", x.Name)
+	}
+	if x.Age < 0 {
+		errs = gen.Fail(errs, ns, "Age", "gte", "0", x.Age)
+	} else if x.Age > 150 {
+		errs = gen.Fail(errs, ns, "Age", "lte", "150", x.Age)
+	}
+	if !(x.Role == "admin" || x.Role == "user") {
+		errs = gen.Fail(errs, ns, "Role", "oneof", "admin user", x.Role)
+	}
+	if x.Nick != nil {
+		if utf8.RuneCountInString(*x.Nick) < 3 {
+			errs = gen.Fail(errs, ns, "Nick", "min", "3", *x.Nick)
+		}
+	}
+	if len(x.Tags) > 3 {
+		errs = gen.Fail(errs, ns, "Tags", "max", "3", x.Tags)
+	} else {
+		for i := range x.Tags {
+			if utf8.RuneCountInString(x.Tags[i]) < 1 {
+				errs = gen.Fail(errs, ns, "Tags["+strconv.Itoa(i)+"]", "min", "1", x.Tags[i])
+			}
+		}
+	}
+	if x.Address != nil {
+		errs = _Address_validate(x.Address, ns+".Address", errs)
+	}
+	for i := range x.Previous {
+		errs = _Address_validate(&x.Previous[i], ns+".Previous["+strconv.Itoa(i)+"]", errs)
+	}
+	return errs
+}
+
+func _Base_decodeForm(x *Base, values url.Values, prefix string, errs form.DecodeErrors) {
+	if vals := values[prefix+"id"]; len(vals) > 0 {
+		if v, ok := gen.Int(vals[0], 64, "int64", prefix+"id", errs); ok {
+			x.ID = v
+		}
+	}
+}
+
+func _Address_decodeForm(x *Address, values url.Values, prefix string, errs form.DecodeErrors) {
+	if vals := values[prefix+"city"]; len(vals) > 0 {
+		x.City = vals[0]
+	}
+	if vals := values[prefix+"zip"]; len(vals) > 0 {
+		x.Zip = vals[0]
+	}
+}
+
+func _Address_decodeJSON(x *Address, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		return
+	}
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+		switch gen.Field(key, "city", "zip") {
+		case 0:
+			if !iter.ReadNil() {
+				x.City = iter.ReadString()
+			}
+		case 1:
+			if !iter.ReadNil() {
+				x.Zip = iter.ReadString()
+			}
+		default:
+			iter.Skip()
+		}
+		return true
+	})
+}
+
+func _Base_validate(x *Base, ns string, errs validator.ValidationErrors) validator.ValidationErrors {
+	return errs
+}
+
+func _Address_validate(x *Address, ns string, errs validator.ValidationErrors) validator.ValidationErrors {
+	if x.City == "" {
+		errs = gen.Fail(errs, ns, "City", "required", "", x.City)
+	}
+	if x.Zip != "" {
+		if utf8.RuneCountInString(x.Zip) != 5 {
+			errs = gen.Fail(errs, ns, "Zip", "len", "5", x.Zip)
+		}
+	}
+	return errs
+}
@@ -255,3 +255,12 @@ func performJSONTest(t *testing.T, binder binderFunc, testCase jsonTestCase) {
 		}
 	})
 }
+
+func BenchmarkJSON(b *testing.B) {
+	payload := `{"title":"Glorious Post Title", "id":1, "author":{"name":"Matt Holt"}, "ratings":[4, 3, 5]}`
+	benchmarkBinder(b, binding.JSON, func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, testRoute+"?content=Lorem+ipsum", strings.NewReader(payload))
+		req.Header.Set("Content-Type", jsonContentType)
+		return req
+	})
+}
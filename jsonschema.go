@@ -0,0 +1,233 @@
+package binding
+
+import (
+	"bytes"
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Option configures a binding middleware. Options are passed to Bind, Form,
+// MultipartForm and JSON after the model, besides an optional interface pointer.
+type Option func(plan *bindingPlan)
+
+const schemaURL = "binding://schema.json"
+
+// JSONSchema is an Option for JSON and Bind that validates JSON request bodies
+// against the JSON Schema doc before they are decoded into the model. doc is
+// the schema document as []byte or string, or any value that encodes to it
+// like the result of SchemaOf. Schemas without $schema are draft 2020-12 and
+// formats are asserted. Every violation is reported as a cause of a 422
+// metav1.Status whose field is the JSON pointer of the offending value.
+// Empty bodies are not validated, as the binder does not decode them either.
+// JSONSchema panics if doc is not a valid schema.
+func JSONSchema(doc interface{}) Option {
+	var data []byte
+	switch doc := doc.(type) {
+	case []byte:
+		data = doc
+	case string:
+		data = []byte(doc)
+	default:
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			panic(fmt.Sprintf("binding: failed to encode JSON Schema: %v", err))
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(data)); err != nil {
+		panic(fmt.Sprintf("binding: invalid JSON Schema: %v", err))
+	}
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		panic(fmt.Sprintf("binding: invalid JSON Schema: %v", err))
+	}
+	schema := &jsonSchema{Schema: compiled}
+	if err := gojson.Unmarshal(data, &schema.doc); err != nil {
+		panic(fmt.Sprintf("binding: invalid JSON Schema: %v", err))
+	}
+	return func(plan *bindingPlan) {
+		plan.schema = schema
+	}
+}
+
+// jsonSchema is a compiled JSON Schema along with its document, which tells
+// the properties a required violation is about.
+type jsonSchema struct {
+	*jsonschema.Schema
+	doc interface{}
+}
+
+// schemaError is a validation error of instance against a jsonSchema.
+type schemaError struct {
+	*jsonschema.ValidationError
+	schema   *jsonSchema
+	instance interface{}
+}
+
+// validateSchema validates the JSON document data against schema. It returns a
+// *schemaError for a document that does not conform.
+func validateSchema(schema *jsonSchema, data []byte) error {
+	dec := gojson.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	if err := schema.Validate(v); err != nil {
+		if ve, ok := err.(*jsonschema.ValidationError); ok {
+			return &schemaError{ValidationError: ve, schema: schema, instance: v}
+		}
+		return err
+	}
+	return nil
+}
+
+// newSchemaError converts the leaves of a JSON Schema validation error to causes.
+// Without the schema and instance of se, a required violation is reported for
+// the object missing the properties rather than for each of them.
+func newSchemaError(err *jsonschema.ValidationError, obj interface{}, se *schemaError) *apierrors.StatusError {
+	gk := qualifiedKind(obj)
+	name := nameOf(obj)
+	if name == "" && se != nil {
+		name = instanceName(se.instance)
+	}
+
+	var causes []metav1.StatusCause
+	var leaves func(ve *jsonschema.ValidationError)
+	leaves = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) > 0 {
+			for _, c := range ve.Causes {
+				leaves(c)
+			}
+			return
+		}
+		if strings.HasSuffix(ve.KeywordLocation, "/required") {
+			if missing := se.missingProperties(ve); missing != nil {
+				for _, prop := range missing {
+					causes = append(causes, metav1.StatusCause{
+						Type:    metav1.CauseTypeFieldValueRequired,
+						Message: "missing property " + strconv.Quote(prop),
+						Field:   ve.InstanceLocation + "/" + url.PathEscape(jsonPointerEscaper.Replace(prop)),
+					})
+				}
+				return
+			}
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: ve.Message,
+				Field:   ve.InstanceLocation,
+			})
+			return
+		}
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: ve.Message,
+			Field:   ve.InstanceLocation,
+		})
+	}
+	leaves(err)
+
+	// properties are validated in random order
+	sort.SliceStable(causes, func(i, j int) bool { return causes[i].Field < causes[j].Field })
+	errs := make([]error, 0, len(causes))
+	for _, c := range causes {
+		errs = append(errs, fmt.Errorf("%s: %s", c.Field, c.Message))
+	}
+
+	return &apierrors.StatusError{metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusUnprocessableEntity,
+		Reason: metav1.StatusReasonInvalid,
+		Details: &metav1.StatusDetails{
+			Group:  gk.Group,
+			Kind:   gk.Kind,
+			Name:   name,
+			Causes: causes,
+		},
+		Message: fmt.Sprintf("%s %q is invalid: %v", gk.String(), name, utilerrors.NewAggregate(errs)),
+	}}
+}
+
+var (
+	jsonPointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// missingProperties returns the properties the required keyword of ve lists
+// that are missing in the object ve is about, or nil if they are unknown.
+func (se *schemaError) missingProperties(ve *jsonschema.ValidationError) []string {
+	if se == nil {
+		return nil
+	}
+	base, ptr, _ := strings.Cut(ve.AbsoluteKeywordLocation, "#")
+	if base != schemaURL {
+		return nil // in a schema with another $id
+	}
+	required, _ := lookupPointer(se.schema.doc, ptr).([]interface{})
+	obj, ok := lookupPointer(se.instance, ve.InstanceLocation).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var missing []string
+	for _, prop := range required {
+		if prop, ok := prop.(string); ok {
+			if _, found := obj[prop]; !found {
+				missing = append(missing, prop)
+			}
+		}
+	}
+	return missing
+}
+
+// lookupPointer returns the value the JSON pointer ptr, with URL escaped
+// tokens like the validator reports them, refers to in doc, or nil.
+func lookupPointer(doc interface{}, ptr string) interface{} {
+	if ptr == "" {
+		return doc
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil
+		}
+		token = jsonPointerUnescaper.Replace(token)
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			doc = v[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			doc = v[i]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
+
+// instanceName is the name of the object in the JSON document v like nameOf
+// reports it for a model, the name in its metadata or its name property.
+func instanceName(v interface{}) string {
+	obj, _ := v.(map[string]interface{})
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		name, _ := metadata["name"].(string)
+		return name
+	}
+	name, _ := obj["name"].(string)
+	return name
+}
@@ -0,0 +1,133 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 2},
+		"email": {"type": "string", "format": "email"},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["name"]
+}`
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		binder func(next http.Handler) http.Handler
+		body   string
+		code   int
+		causes []metav1.StatusCause
+		object string
+	}{
+		{
+			name:   "valid",
+			binder: binding.JSON(Person{}, binding.JSONSchema(personSchema)),
+			body:   `{"name":"John","email":"john@example.com"}`,
+			code:   http.StatusOK,
+		},
+		{
+			name:   "violations",
+			binder: binding.JSON(Person{}, binding.JSONSchema([]byte(personSchema))),
+			body:   `{"name":"J","email":"john","tags":["a",1]}`,
+			code:   http.StatusUnprocessableEntity,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "/email"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "/name"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "/tags/1"},
+			},
+			object: "J",
+		},
+		{
+			name:   "missing property",
+			binder: binding.Bind(Person{}, (*modeler)(nil), binding.JSONSchema(personSchema)),
+			body:   `{"email":"john@example.com"}`,
+			code:   http.StatusUnprocessableEntity,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueRequired, Field: "/name"},
+			},
+		},
+		{
+			name:   "missing properties",
+			binder: binding.JSON(Person{}, binding.JSONSchema(`{"type": "object", "required": ["name", "e-mail", "a/b"]}`)),
+			body:   `{}`,
+			code:   http.StatusUnprocessableEntity,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueRequired, Field: "/a~1b"},
+				{Type: metav1.CauseTypeFieldValueRequired, Field: "/e-mail"},
+				{Type: metav1.CauseTypeFieldValueRequired, Field: "/name"},
+			},
+		},
+		{
+			name:   "missing nested property",
+			binder: binding.JSON(Person{}, binding.JSONSchema(`{"properties": {"a b": {"$ref": "#/$defs/address"}}, "$defs": {"address": {"required": ["city"]}}}`)),
+			body:   `{"name":"John","a b":{}}`,
+			code:   http.StatusUnprocessableEntity,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueRequired, Field: "/a%20b/city"},
+			},
+			object: "John",
+		},
+		{
+			name:   "schema from SchemaOf",
+			binder: binding.JSON(Person{}, binding.JSONSchema(binding.SchemaOf(Person{}))),
+			body:   `{"email":"john@example.com"}`,
+			code:   http.StatusUnprocessableEntity,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueRequired, Field: "/name"},
+			},
+		},
+		{
+			name:   "malformed",
+			binder: binding.JSON(Person{}, binding.JSONSchema(personSchema)),
+			body:   `{"name":`,
+			code:   http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.With(tt.binder).Post(testRoute, binding.HandlerFunc(func(p Person) []byte { return nil }))
+
+			req := httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			if tt.causes == nil {
+				return
+			}
+			var status metav1.Status
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			assert.Equal(t, metav1.StatusReasonInvalid, status.Reason)
+			require.NotNil(t, status.Details)
+			assert.Equal(t, "Person", status.Details.Kind)
+			assert.Equal(t, tt.object, status.Details.Name)
+			require.Len(t, status.Details.Causes, len(tt.causes))
+			for i, cause := range tt.causes {
+				assert.Equal(t, cause.Type, status.Details.Causes[i].Type)
+				assert.Equal(t, cause.Field, status.Details.Causes[i].Field)
+				assert.NotEmpty(t, status.Details.Causes[i].Message)
+			}
+		})
+	}
+
+	assert.Panics(t, func() { binding.JSONSchema(`{"type": 1}`) })
+}
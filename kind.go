@@ -0,0 +1,95 @@
+package binding
+
+import (
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupKinder is implemented by binding models that know the API group and kind
+// they are served as. NewBindingError reports them in metav1.StatusDetails.
+type GroupKinder interface {
+	GroupKind() schema.GroupKind
+}
+
+var groupKinds = map[reflect.Type]schema.GroupKind{}
+
+// RegisterGroupKind reports binding errors for models of the type of obj with
+// the API group and kind gk, for types that can not implement GroupKinder
+// themselves. RegisterGroupKind is not safe to call while serving requests.
+func RegisterGroupKind(obj interface{}, gk schema.GroupKind) {
+	groupKinds[reflect.TypeOf(obj)] = gk
+}
+
+// qualifiedKind returns the API group and kind of obj. If neither is known, the
+// kind is the name of the Go type of obj.
+func qualifiedKind(obj interface{}) schema.GroupKind {
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return schema.GroupKind{}
+	}
+	if gk, ok := groupKinds[t]; ok {
+		return gk
+	}
+	if gker, ok := addressable(obj).(GroupKinder); ok {
+		return gker.GroupKind()
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return schema.GroupKind{Kind: t.Name()}
+	}
+	return schema.GroupKind{Kind: t.String()}
+}
+
+// nameOf returns the name of a bound object: the name in its ObjectMeta if it is
+// a metav1.Object, else the value of a string field tagged json:"name" or called
+// Name.
+func nameOf(obj interface{}) string {
+	if o, ok := addressable(obj).(metav1.Object); ok {
+		return o.GetName()
+	}
+
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	t := v.Type()
+	byName := -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name == "name" {
+			return v.Field(i).String()
+		}
+		if f.Name == "Name" && byName < 0 {
+			byName = i
+		}
+	}
+	if byName >= 0 {
+		return v.Field(byName).String()
+	}
+	return ""
+}
+
+// addressable returns a pointer to a copy of obj if obj is not a pointer, so
+// that methods with pointer receivers like those of an embedded
+// metav1.ObjectMeta can be found.
+func addressable(obj interface{}) interface{} {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() || v.Kind() == reflect.Ptr {
+		return obj
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr.Interface()
+}
@@ -0,0 +1,81 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type widget struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Color             string `json:"color" validate:"required"`
+}
+
+func (widget) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: "example.com", Kind: "Widget"}
+}
+
+func TestBindingErrorDetails(t *testing.T) {
+	binding.RegisterGroupKind(Group{}, schema.GroupKind{Group: "people.example.com", Kind: "Group"})
+
+	tests := []struct {
+		name    string
+		binder  func(next http.Handler) http.Handler
+		body    string
+		details metav1.StatusDetails
+		message string
+	}{
+		{
+			name:    "GroupKinder with ObjectMeta",
+			binder:  binding.JSON(widget{}),
+			body:    `{"metadata":{"name":"knob"}}`,
+			details: metav1.StatusDetails{Group: "example.com", Kind: "Widget", Name: "knob"},
+			message: `Widget.example.com "knob" is invalid: Key: 'widget.Color' Error:Field validation for 'Color' failed on the 'required' tag`,
+		},
+		{
+			name:    "registered",
+			binder:  binding.JSON(Group{}),
+			body:    `{"name":"admins"}`,
+			details: metav1.StatusDetails{Group: "people.example.com", Kind: "Group", Name: "admins"},
+			message: `Group.people.example.com "admins" is invalid: Key: 'Group.People' Error:Field validation for 'People' failed on the 'min' tag`,
+		},
+		{
+			name:    "name field",
+			binder:  binding.JSON(Person{}),
+			body:    `{"email":"john@example.com"}`,
+			details: metav1.StatusDetails{Kind: "Person"},
+			message: `Person "" is invalid: Key: 'Person.Name' Error:Field validation for 'Name' failed on the 'required' tag`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.With(tt.binder).Post(testRoute, binding.HandlerFunc(func() []byte { return nil }))
+
+			req := httptest.NewRequest(http.MethodPost, testRoute, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			var status metav1.Status
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			assert.Equal(t, tt.message, status.Message)
+			if assert.NotNil(t, status.Details) {
+				status.Details.Causes = nil
+				assert.Equal(t, tt.details, *status.Details)
+			}
+		})
+	}
+}
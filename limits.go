@@ -0,0 +1,179 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Limits bound the input the binders accept, so that a request can not make
+// them allocate huge slices or recurse arbitrarily deep. A zero field does not
+// limit anything, except that a zero MaxArrayIndex leaves the limit of 10000
+// elements go-playground/form has by default in place.
+type Limits struct {
+	// MaxKeys is the number of form keys, or of the members of each object in
+	// a JSON body. More are rejected with 413 Request Entity Too Large.
+	MaxKeys int
+
+	// MaxArrayIndex is the largest index of a slice or array in a form key,
+	// like 99 in rating[99]. Larger ones are rejected with 400 Bad Request.
+	MaxArrayIndex int
+
+	// MaxDepth is the nesting depth of a form key, one for title and three for
+	// people[0].name, or of the objects and arrays of a JSON body. Deeper
+	// input is rejected with 400 Bad Request.
+	MaxDepth int
+
+	// MaxStringLength is the length in bytes of a form value or of a JSON
+	// string as it is written in the body, so an escape sequence like \u00e9
+	// counts six bytes. Longer ones are rejected with 413 Request Entity Too
+	// Large.
+	MaxStringLength int
+}
+
+// DefaultLimits are the Limits of the binding middleware created without the
+// Limit option. Changes only apply to middleware created afterwards.
+var DefaultLimits = Limits{
+	MaxKeys:         1000,
+	MaxArrayIndex:   10000,
+	MaxDepth:        32,
+	MaxStringLength: 1 << 20,
+}
+
+// Limit is an Option that replaces DefaultLimits for a binding middleware:
+//
+//	limits := binding.DefaultLimits
+//	limits.MaxStringLength = 64 << 10
+//	r.With(binding.JSON(Post{}, binding.Limit(limits))).Post("/posts", createPost)
+func Limit(limits Limits) Option {
+	return func(plan *bindingPlan) {
+		plan.setLimits(limits)
+	}
+}
+
+// defaultMaxArraySize is the array size go-playground/form limits slices to by
+// default.
+const defaultMaxArraySize = 10000
+
+// setLimits sets the limits of the plan, including the array size of its
+// form decoders, so that indexes are checked during decoding as well.
+func (plan *bindingPlan) setLimits(limits Limits) {
+	plan.limits = limits
+	size := uint(defaultMaxArraySize)
+	if limits.MaxArrayIndex > 0 {
+		size = uint(limits.MaxArrayIndex) + 1
+	}
+	plan.form.SetMaxArraySize(size)
+	plan.query.SetMaxArraySize(size)
+}
+
+// checkValues checks the form values against the limits before they are decoded.
+func (l *Limits) checkValues(values url.Values) *apierrors.StatusError {
+	if l.MaxKeys > 0 && len(values) > l.MaxKeys {
+		return apierrors.NewRequestEntityTooLargeError(fmt.Sprintf("%d form keys exceed the limit of %d", len(values), l.MaxKeys))
+	}
+	for key, vals := range values {
+		if l.MaxDepth > 0 && strings.Count(key, ".")+strings.Count(key, "[")+1 > l.MaxDepth {
+			return apierrors.NewBadRequest(fmt.Sprintf("form key %.64q is nested deeper than %d", key, l.MaxDepth))
+		}
+		if l.MaxArrayIndex > 0 {
+			for rest := key; ; {
+				_, index, ok := strings.Cut(rest, "[")
+				if !ok {
+					break
+				}
+				index, rest, _ = strings.Cut(index, "]")
+				i, err := strconv.ParseUint(index, 10, 64)
+				if errors.Is(err, strconv.ErrRange) || err == nil && i > uint64(l.MaxArrayIndex) {
+					return apierrors.NewBadRequest(fmt.Sprintf("index %s of form key %.64q exceeds the limit of %d", index, key, l.MaxArrayIndex))
+				}
+			}
+		}
+		if l.MaxStringLength > 0 {
+			for _, v := range vals {
+				if len(v) > l.MaxStringLength {
+					return apierrors.NewRequestEntityTooLargeError(fmt.Sprintf("value of form key %.64q exceeds the maximum length of %d", key, l.MaxStringLength))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonReader returns a reader that checks the JSON read from r against the
+// limits while it is decoded.
+func (l *Limits) jsonReader(r io.Reader) *limitedJSON {
+	return &limitedJSON{r: r, limits: l}
+}
+
+// limitedJSON scans the JSON read through it, failing with err as soon as it
+// exceeds the limits. It does not validate the JSON, which is up to the decoder.
+type limitedJSON struct {
+	r      io.Reader
+	limits *Limits
+	err    *apierrors.StatusError
+
+	depth    int
+	members  []int // members of the objects and arrays being read, innermost last
+	inString bool
+	escaped  bool
+	length   int
+}
+
+func (l *limitedJSON) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	n, err := l.r.Read(p)
+	for i := 0; i < n && l.err == nil; i++ {
+		c := p[i]
+		if l.inString {
+			switch {
+			case l.escaped:
+				l.escaped = false
+			case c == '\\':
+				l.escaped = true
+			case c == '"':
+				l.inString = false
+				continue
+			}
+			l.length++
+			if l.limits.MaxStringLength > 0 && l.length > l.limits.MaxStringLength {
+				l.err = apierrors.NewRequestEntityTooLargeError(fmt.Sprintf("JSON string exceeds the maximum length of %d", l.limits.MaxStringLength))
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			l.inString, l.length = true, 0
+		case '{', '[':
+			l.depth++
+			if l.limits.MaxDepth > 0 && l.depth > l.limits.MaxDepth {
+				l.err = apierrors.NewBadRequest(fmt.Sprintf("JSON is nested deeper than %d", l.limits.MaxDepth))
+			}
+			l.members = append(l.members, 0)
+		case '}', ']':
+			l.depth--
+			if len(l.members) > 0 {
+				l.members = l.members[:len(l.members)-1]
+			}
+		case ':':
+			if len(l.members) == 0 {
+				break // invalid JSON, left to the decoder
+			}
+			l.members[len(l.members)-1]++
+			if l.limits.MaxKeys > 0 && l.members[len(l.members)-1] > l.limits.MaxKeys {
+				l.err = apierrors.NewRequestEntityTooLargeError(fmt.Sprintf("JSON object members exceed the limit of %d", l.limits.MaxKeys))
+			}
+		}
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return n, err
+}
@@ -0,0 +1,162 @@
+package binding_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/bindingtest"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLimits(t *testing.T) {
+	limits := binding.Limits{MaxKeys: 4, MaxArrayIndex: 9, MaxDepth: 3, MaxStringLength: 16}
+	limited := func(binder bindingtest.Binder) bindingtest.Binder {
+		return func(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+			return binder(obj, append(ifacePtr, binding.Limit(limits))...)
+		}
+	}
+	group := `{"name":"x","people":[{"name":"y"}]}`
+
+	tests := []struct {
+		name    string
+		binder  bindingtest.Binder
+		req     *bindingtest.Request
+		code    int
+		reason  metav1.StatusReason
+		message string
+	}{
+		{
+			name:   "form within limits",
+			binder: binding.Form,
+			req:    bindingtest.Post(testRoute).Form("Name", "x").Form("People[9].name", "y"),
+			code:   http.StatusOK,
+		},
+		{
+			name:    "too many form keys",
+			binder:  binding.Form,
+			req:     bindingtest.Post(testRoute).Form("Name", "x").Form("a", "").Form("b", "").Form("c", "").Form("d", ""),
+			code:    http.StatusRequestEntityTooLarge,
+			reason:  metav1.StatusReasonRequestEntityTooLarge,
+			message: "5 form keys exceed the limit of 4",
+		},
+		{
+			name:    "array index",
+			binder:  binding.Form,
+			req:     bindingtest.Post(testRoute).Form("Name", "x").Form("People[10].name", "y"),
+			code:    http.StatusBadRequest,
+			reason:  metav1.StatusReasonBadRequest,
+			message: "index 10 of form key",
+		},
+		{
+			name:    "huge array index",
+			binder:  binding.Form,
+			req:     bindingtest.Post(testRoute).Form("People[99999999999999999999999].name", "y"),
+			code:    http.StatusBadRequest,
+			reason:  metav1.StatusReasonBadRequest,
+			message: "index 99999999999999999999999 of form key",
+		},
+		{
+			name:    "form nesting",
+			binder:  binding.Form,
+			req:     bindingtest.Post(testRoute).Form("Name", "x").Form("People[0].name.First", "y"),
+			code:    http.StatusBadRequest,
+			reason:  metav1.StatusReasonBadRequest,
+			message: "is nested deeper than 3",
+		},
+		{
+			name:    "form value length",
+			binder:  binding.MultipartForm,
+			req:     bindingtest.Post(testRoute).Form("Name", strings.Repeat("x", 17)).Multipart(),
+			code:    http.StatusRequestEntityTooLarge,
+			reason:  metav1.StatusReasonRequestEntityTooLarge,
+			message: `value of form key "Name" exceeds the maximum length of 16`,
+		},
+		{
+			name:    "query",
+			binder:  binding.JSON,
+			req:     bindingtest.Post(testRoute).Query("name", strings.Repeat("x", 17)).JSON(group),
+			code:    http.StatusRequestEntityTooLarge,
+			reason:  metav1.StatusReasonRequestEntityTooLarge,
+			message: `value of form key "name"`,
+		},
+		{
+			name:   "JSON within limits",
+			binder: binding.JSON,
+			req:    bindingtest.Post(testRoute).JSON(group),
+			code:   http.StatusOK,
+		},
+		{
+			name:    "JSON nesting",
+			binder:  binding.JSON,
+			req:     bindingtest.Post(testRoute).JSON(`{"name":"x","people":[{"name":"y","x":[]}]}`),
+			code:    http.StatusBadRequest,
+			reason:  metav1.StatusReasonBadRequest,
+			message: "JSON is nested deeper than 3",
+		},
+		{
+			name:    "JSON string length",
+			binder:  binding.Bind,
+			req:     bindingtest.Post(testRoute).JSON(`{"name":"x","people":[{"name":"` + strings.Repeat(`\"`, 9) + `"}]}`),
+			code:    http.StatusRequestEntityTooLarge,
+			reason:  metav1.StatusReasonRequestEntityTooLarge,
+			message: "JSON string exceeds the maximum length of 16",
+		},
+		{
+			name:    "JSON object members",
+			binder:  binding.JSON,
+			req:     bindingtest.Post(testRoute).JSON(`{"name":"x","people":[{"name":"a:b"}],"a":1,"b":2,"c":3}`),
+			code:    http.StatusRequestEntityTooLarge,
+			reason:  metav1.StatusReasonRequestEntityTooLarge,
+			message: "JSON object members exceed the limit of 4",
+		},
+		{
+			name:   "JSON members of many objects",
+			binder: binding.JSON,
+			req:    bindingtest.Post(testRoute).JSON(`{"name":"x","people":[{"name":"y"},{"name":"z"},{"name":"a"},{"name":"b"}]}`),
+			code:   http.StatusOK,
+		},
+		{
+			name: "JSON with schema",
+			binder: func(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+				return binding.JSON(obj, binding.JSONSchema(binding.SchemaOf(obj)), binding.Limit(limits))
+			},
+			req:     bindingtest.Post(testRoute).JSON(`[[[[]]]]`),
+			code:    http.StatusBadRequest,
+			reason:  metav1.StatusReasonBadRequest,
+			message: "JSON is nested deeper than 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp := bindingtest.Run[Group](t, limited(tt.binder), tt.req)
+			if bindingtest.AssertCode(t, resp, tt.code) && tt.reason != "" {
+				bindingtest.AssertReason(t, resp, tt.reason)
+				assert.Contains(t, bindingtest.DecodeStatus(t, resp).Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestDefaultLimits(t *testing.T) {
+	_, resp := bindingtest.Run[BlogPost](t, binding.Form, bindingtest.Post(testRoute).Form("rating[10001]", "1"))
+	bindingtest.AssertCode(t, resp, http.StatusBadRequest)
+
+	_, resp = bindingtest.Run[BlogPost](t, binding.JSON, bindingtest.Post(testRoute).JSON(strings.Repeat("[", 33)))
+	bindingtest.AssertCode(t, resp, http.StatusBadRequest)
+	assert.Contains(t, bindingtest.DecodeStatus(t, resp).Message, "nested deeper than 32")
+
+	// without limits go-playground/form still limits the array size
+	noLimits := func(obj interface{}, ifacePtr ...interface{}) func(next http.Handler) http.Handler {
+		return binding.Form(obj, binding.Limit(binding.Limits{}))
+	}
+	post, resp := bindingtest.Run[BlogPost](t, noLimits, bindingtest.Post(testRoute).Form("title", "x").Form("id", "1").Form("author.name", "y").Form("rating[9999]", "1"))
+	bindingtest.AssertCode(t, resp, http.StatusOK)
+	assert.Len(t, post.Ratings, 10000)
+
+	_, resp = bindingtest.Run[BlogPost](t, noLimits, bindingtest.Post(testRoute).Form("title", "x").Form("id", "1").Form("author.name", "y").Form("rating[10001]", "1"))
+	bindingtest.AssertCode(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,139 @@
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"go.wandrs.dev/inject"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	listOptionsType   = reflect.TypeOf(metav1.ListOptions{})
+	labelSelectorType = reflect.TypeOf((*labels.Selector)(nil)).Elem()
+	fieldSelectorType = reflect.TypeOf((*fields.Selector)(nil)).Elem()
+)
+
+// ListOptions is middleware to bind the query parameters of a Kubernetes style
+// list request to metav1.ListOptions. The parsed labelSelector and fieldSelector
+// are injected as labels.Selector and fields.Selector, which select everything
+// if the parameter is missing. Malformed parameters and selectors, a negative
+// limit or a continue token that is combined with watch or resourceVersion are
+// rejected with a 400 metav1.Status whose causes point at the parameter.
+func ListOptions() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return injecting([]reflect.Type{listOptionsType, labelSelectorType, fieldSelectorType}, func(w http.ResponseWriter, r *http.Request) {
+			injector, _ := r.Context().Value(injectorKey{}).(inject.Injector)
+			if injector == nil {
+				panic("chi: register Injector middleware")
+			}
+			if err := bindListOptions(r, injector); err != nil {
+				writeError(injector, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bindListOptions(r *http.Request, injector inject.Injector) *apierrors.StatusError {
+	var query url.Values
+	if r.URL != nil {
+		query = r.URL.Query()
+	}
+
+	var errs field.ErrorList
+	opts := metav1.ListOptions{
+		LabelSelector:        query.Get("labelSelector"),
+		FieldSelector:        query.Get("fieldSelector"),
+		ResourceVersion:      query.Get("resourceVersion"),
+		ResourceVersionMatch: metav1.ResourceVersionMatch(query.Get("resourceVersionMatch")),
+		Continue:             query.Get("continue"),
+	}
+	parseBool := func(name string, out *bool) {
+		if s := query.Get(name); s != "" {
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				errs = append(errs, field.Invalid(field.NewPath(name), s, "must be a boolean"))
+			}
+			*out = v
+		}
+	}
+	parseInt := func(name string) *int64 {
+		s := query.Get(name)
+		if s == "" {
+			return nil
+		}
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			errs = append(errs, field.Invalid(field.NewPath(name), s, "must be an integer"))
+			return nil
+		}
+		return &v
+	}
+	parseBool("watch", &opts.Watch)
+	parseBool("allowWatchBookmarks", &opts.AllowWatchBookmarks)
+	opts.TimeoutSeconds = parseInt("timeoutSeconds")
+	if limit := parseInt("limit"); limit != nil {
+		opts.Limit = *limit
+	}
+
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("labelSelector"), opts.LabelSelector, err.Error()))
+	}
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("fieldSelector"), opts.FieldSelector, err.Error()))
+	}
+	if opts.Limit < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("limit"), opts.Limit, "must be greater than or equal to 0"))
+	}
+	if opts.TimeoutSeconds != nil && *opts.TimeoutSeconds < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("timeoutSeconds"), *opts.TimeoutSeconds, "must be greater than or equal to 0"))
+	}
+	if opts.Continue != "" {
+		if opts.Watch {
+			errs = append(errs, field.Forbidden(field.NewPath("continue"), "not allowed when watch is set"))
+		}
+		if opts.ResourceVersion != "" {
+			errs = append(errs, field.Forbidden(field.NewPath("continue"), "not allowed when resourceVersion is set"))
+		}
+	}
+	if len(errs) > 0 {
+		return newListOptionsError(errs)
+	}
+
+	injector.Set(listOptionsType, reflect.ValueOf(opts))
+	injector.Set(labelSelectorType, reflect.ValueOf(labelSelector))
+	injector.Set(fieldSelectorType, reflect.ValueOf(fieldSelector))
+	return nil
+}
+
+func newListOptionsError(errs field.ErrorList) *apierrors.StatusError {
+	causes := make([]metav1.StatusCause, 0, len(errs))
+	for _, err := range errs {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseType(err.Type),
+			Message: err.ErrorBody(),
+			Field:   err.Field,
+		})
+	}
+	return &apierrors.StatusError{metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusBadRequest,
+		Reason: metav1.StatusReasonBadRequest,
+		Details: &metav1.StatusDetails{
+			Causes: causes,
+		},
+		Message: fmt.Sprintf("invalid list options: %v", errs.ToAggregate()),
+	}}
+}
@@ -0,0 +1,103 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestListOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		code   int
+		opts   metav1.ListOptions
+		labels string
+		fields string
+		causes []metav1.StatusCause
+	}{
+		{
+			name: "empty",
+			code: http.StatusOK,
+		},
+		{
+			name:  "selectors and paging",
+			query: "?labelSelector=app%3Dweb,tier!%3Ddb&fieldSelector=metadata.name%3Djohn&limit=10&continue=abc",
+			code:  http.StatusOK,
+			opts: metav1.ListOptions{
+				LabelSelector: "app=web,tier!=db",
+				FieldSelector: "metadata.name=john",
+				Limit:         10,
+				Continue:      "abc",
+			},
+			labels: "app=web,tier!=db",
+			fields: "metadata.name=john",
+		},
+		{
+			name:  "invalid selectors",
+			query: "?labelSelector=app%3D%3D%3D&fieldSelector=metadata.name",
+			code:  http.StatusBadRequest,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "labelSelector"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "fieldSelector"},
+			},
+		},
+		{
+			name:  "invalid limit",
+			query: "?limit=-1&watch=maybe",
+			code:  http.StatusBadRequest,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "watch"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Field: "limit"},
+			},
+		},
+		{
+			name:  "continue with watch",
+			query: "?continue=abc&watch=true",
+			code:  http.StatusBadRequest,
+			causes: []metav1.StatusCause{
+				{Type: metav1.CauseType(field.ErrorTypeForbidden), Field: "continue"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.With(binding.ListOptions()).Get("/", binding.HandlerFunc(func(opts metav1.ListOptions, ls labels.Selector, fs fields.Selector) []byte {
+				assert.Equal(t, tt.opts, opts)
+				assert.Equal(t, tt.labels, ls.String())
+				assert.Equal(t, tt.fields, fs.String())
+				return nil
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			if tt.causes != nil {
+				var status metav1.Status
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+				assert.Equal(t, metav1.StatusReasonBadRequest, status.Reason)
+				if assert.NotNil(t, status.Details) && assert.Len(t, status.Details.Causes, len(tt.causes)) {
+					for i, cause := range tt.causes {
+						assert.Equal(t, cause.Type, status.Details.Causes[i].Type)
+						assert.Equal(t, cause.Field, status.Details.Causes[i].Field)
+					}
+				}
+			}
+		})
+	}
+}
@@ -156,3 +156,19 @@ func makeMultipartPayload(testCase multipartFormTestCase) (*bytes.Buffer, *multi
 		return body, writer
 	}
 }
+
+func BenchmarkMultipartForm(b *testing.B) {
+	multipartPayload, mpWriter := makeMultipartPayload(multipartFormTestCase{
+		expected: BlogPost{Post: Post{Title: "Glorious Post Title"}, Id: 1, Author: Person{Name: "Matt Holt"}, Ratings: []int{3, 5, 4}},
+	})
+	if err := mpWriter.Close(); err != nil {
+		b.Fatal(err)
+	}
+	payload := multipartPayload.Bytes()
+
+	benchmarkBinder(b, binding.MultipartForm, func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, testRoute, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", mpWriter.FormDataContentType())
+		return req
+	})
+}
@@ -0,0 +1,248 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.wandrs.dev/inject"
+
+	httpw "go.wandrs.dev/http"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Encoder writes v to w in the media type it is registered for.
+type Encoder func(w io.Writer, v interface{}) error
+
+type encoder struct {
+	mediaType string
+	encode    Encoder
+}
+
+const jsonMediaType = "application/json"
+
+// encoders are tried in the order they are registered when the client accepts
+// several media types equally, so JSON stays the default. Other media types
+// are only written once registered, as browsers accept application/xml with a
+// higher quality than the */* that JSON matches.
+var encoders = []encoder{
+	{mediaType: jsonMediaType, encode: func(w io.Writer, v interface{}) error {
+		return json.NewEncoder(w).Encode(v)
+	}},
+}
+
+// RegisterEncoder makes HandlerFunc write results and errors with enc when the
+// Accept header of a request prefers mediaType, for example
+//
+//	binding.RegisterEncoder("application/yaml", func(w io.Writer, v interface{}) error {
+//		data, err := yaml.Marshal(v)
+//		if err != nil {
+//			return err
+//		}
+//		_, err = w.Write(data)
+//		return err
+//	})
+//
+// Registering a media type again replaces its encoder, and a nil enc removes
+// it. application/json is always written using render.Render and can not be
+// removed. RegisterEncoder is not safe to call while serving requests.
+func RegisterEncoder(mediaType string, enc Encoder) {
+	mediaType = strings.ToLower(mediaType)
+	if mediaType == jsonMediaType && enc == nil {
+		panic("binding: application/json can not be unregistered")
+	}
+	for i := range encoders {
+		if encoders[i].mediaType == mediaType {
+			if enc == nil {
+				encoders = append(encoders[:i], encoders[i+1:]...)
+			} else {
+				encoders[i].encode = enc
+			}
+			return
+		}
+	}
+	if enc != nil {
+		encoders = append(encoders, encoder{mediaType: mediaType, encode: enc})
+	}
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	params    map[string]string // without q
+	q         float64
+}
+
+// parseAccept parses an Accept header, skipping malformed entries.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		r := mediaRange{mediaType: mediaType, params: params, q: 1}
+		if q, ok := params["q"]; ok {
+			if r.q, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+			delete(params, "q")
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// specificity returns how closely r matches mediaType, or -1 if it does not.
+// Media ranges with parameters other than charset ask for a variant of the
+// media type. The only variant HandlerFunc can produce is a metav1.Table,
+// requested with as=Table;g=meta.k8s.io;v=v1 like kube-apiserver does.
+func (r mediaRange) specificity(mediaType string, table bool) int {
+	for k := range r.params {
+		switch k {
+		case "charset", "as", "g", "v":
+		default:
+			return -1
+		}
+	}
+	asTable := r.params["as"] == "Table" && r.params["g"] == metav1.GroupName && r.params["v"] == "v1"
+	if table != asTable || (!table && (r.params["as"] != "" || r.params["g"] != "" || r.params["v"] != "")) {
+		return -1
+	}
+
+	var spec int
+	switch {
+	case r.mediaType == mediaType:
+		spec = 2
+	case r.mediaType == "*/*":
+		spec = 0
+	case strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(mediaType, r.mediaType[:len(r.mediaType)-1]):
+		spec = 1
+	default:
+		return -1
+	}
+	if table {
+		spec++ // a range with parameters is more specific than the same range without
+	}
+	return spec
+}
+
+// negotiate picks the registered encoder preferred by the Accept header of req
+// and whether the value should be converted to a metav1.Table first, which is
+// only considered if table is true. It returns false if the client accepts none
+// of them.
+func negotiate(req *http.Request, table bool) (enc encoder, asTable bool, ok bool) {
+	header := req.Header.Get("Accept")
+	if header == "" {
+		return encoders[0], false, true
+	}
+
+	var (
+		bestQ    float64
+		bestSpec = -1
+		accepted = parseAccept(header)
+	)
+	for _, candidate := range encoders {
+		for _, t := range []bool{false, true} {
+			if t && !table {
+				continue
+			}
+			// the most specific matching range decides the quality of the media type
+			q, spec := 0.0, -1
+			for _, r := range accepted {
+				if s := r.specificity(candidate.mediaType, t); s > spec {
+					q, spec = r.q, s
+				}
+			}
+			if spec < 0 || q <= 0 {
+				continue
+			}
+			if !ok || q > bestQ || (q == bestQ && spec > bestSpec) {
+				enc, asTable, ok = candidate, t, true
+				bestQ, bestSpec = q, spec
+			}
+		}
+	}
+	return enc, asTable, ok
+}
+
+// writeValue writes v with the encoder negotiated for req, or a 406 metav1.Status
+// if the client accepts none of them. v is converted to a metav1.Table first if
+// the client asks for one and v can be converted.
+func writeValue(w http.ResponseWriter, req *http.Request, injector inject.Injector, status int, v interface{}) {
+	convert := tableConvertorOf(v)
+	enc, asTable, ok := negotiate(req, convert != nil)
+	if !ok {
+		ResponseWriter(injector).JSON(http.StatusNotAcceptable, notAcceptable(req))
+		return
+	}
+	if asTable {
+		table, err := convertToTable(req, convert)
+		if err != nil {
+			writeError(injector, err)
+			return
+		}
+		v = table
+	}
+	encode(w, injector, enc, status, v)
+}
+
+// encode writes v with enc. v is encoded before anything is written, so that a
+// value enc can not encode, like a map by encoding/xml, is written as a 500 metav1.Status
+// instead of a truncated body. The status of a failed Status is written as JSON.
+func encode(w http.ResponseWriter, injector inject.Injector, enc encoder, status int, v interface{}) {
+	if enc.mediaType == jsonMediaType {
+		ResponseWriter(injector).JSON(status, v)
+		return
+	}
+	var buf bytes.Buffer
+	if err := enc.encode(&buf, v); err != nil {
+		err = apierrors.NewInternalError(fmt.Errorf("failed to encode %T as %s: %w", v, enc.mediaType, err))
+		if _, ok := v.(*metav1.Status); ok {
+			ResponseWriter(injector).APIError(err)
+		} else {
+			writeError(injector, err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", enc.mediaType)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeStatus writes the metav1.Status of err with the encoder negotiated for
+// req. If the client accepts none of them, the error is written as JSON rather
+// than being replaced with a 406.
+func writeStatus(injector inject.Injector, err error) {
+	ww := ResponseWriter(injector)
+	req, _ := injector.GetVal(requestType).Interface().(*http.Request)
+	if req != nil {
+		if enc, _, ok := negotiate(req, false); ok && enc.mediaType != jsonMediaType {
+			status := httpw.ErrorToAPIStatus(err)
+			encode(ww, injector, enc, int(status.Code), status)
+			return
+		}
+	}
+	ww.APIError(err)
+}
+
+func notAcceptable(req *http.Request) *metav1.Status {
+	mediaTypes := make([]string, len(encoders))
+	for i, enc := range encoders {
+		mediaTypes[i] = enc.mediaType
+	}
+	return &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotAcceptable,
+		Reason:  metav1.StatusReasonNotAcceptable,
+		Message: "only the following media types are accepted: " + strings.Join(mediaTypes, ", "),
+	}
+}
@@ -0,0 +1,313 @@
+package binding_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// registerXML registers encoding/xml for application/xml until t ends.
+func registerXML(t *testing.T) {
+	binding.RegisterEncoder("application/xml", func(w io.Writer, v interface{}) error {
+		return xml.NewEncoder(w).Encode(v)
+	})
+	t.Cleanup(func() { binding.RegisterEncoder("application/xml", nil) })
+}
+
+func TestHandlerFuncNegotiate(t *testing.T) {
+	registerXML(t)
+	binding.RegisterEncoder("text/plain", func(w io.Writer, v interface{}) error {
+		if p, ok := v.(Person); ok {
+			_, err := io.WriteString(w, p.Name)
+			return err
+		}
+		return errors.New("unsupported")
+	})
+	t.Cleanup(func() { binding.RegisterEncoder("text/plain", nil) })
+
+	tests := []struct {
+		name        string
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "no Accept header",
+			code:        http.StatusOK,
+			contentType: "application/json; charset=UTF-8",
+			body:        "{\n  \"name\": \"John\"\n}\n",
+		},
+		{
+			name:        "any media type",
+			accept:      "*/*",
+			code:        http.StatusOK,
+			contentType: "application/json; charset=UTF-8",
+			body:        "{\n  \"name\": \"John\"\n}\n",
+		},
+		{
+			name:        "xml",
+			accept:      "application/xml",
+			code:        http.StatusOK,
+			contentType: "application/xml",
+			body:        "<Person><Name>John</Name><Email></Email></Person>",
+		},
+		{
+			name:        "xml preferred by quality",
+			accept:      "application/json;q=0.5, application/xml",
+			code:        http.StatusOK,
+			contentType: "application/xml",
+			body:        "<Person><Name>John</Name><Email></Email></Person>",
+		},
+		{
+			name:        "registered encoder",
+			accept:      "text/*",
+			code:        http.StatusOK,
+			contentType: "text/plain",
+			body:        "John",
+		},
+		{
+			name:        "specific range wins over wildcard",
+			accept:      "*/*;q=0.8, application/xml;q=0.9, application/json;q=0",
+			code:        http.StatusOK,
+			contentType: "application/xml",
+			body:        "<Person><Name>John</Name><Email></Email></Person>",
+		},
+		{
+			name:        "not acceptable",
+			accept:      "image/png",
+			code:        http.StatusNotAcceptable,
+			contentType: "application/json; charset=UTF-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New(render.Options{IndentJSON: true})))
+			m.Get("/", binding.HandlerFunc(func() Person { return Person{Name: "John"} }))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerFuncNegotiateBrowser(t *testing.T) {
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/", binding.HandlerFunc(func() map[string]string { return map[string]string{"name": "John"} }))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=UTF-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"John"}`, w.Body.String())
+}
+
+func TestHandlerFuncNegotiateError(t *testing.T) {
+	registerXML(t)
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/", binding.HandlerFunc(func() (Person, error) {
+		return Person{}, apierrors.NewNotFound(schema.GroupResource{Resource: "people"}, "john")
+	}))
+	m.Get("/plain", binding.HandlerFunc(func() (Person, error) {
+		return Person{}, errors.New("failed")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<Reason>NotFound</Reason>")
+
+	// errors are still written when no media type is acceptable
+	req = httptest.NewRequest(http.MethodGet, "/plain", nil)
+	req.Header.Set("Accept", "image/png")
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json; charset=UTF-8", w.Header().Get("Content-Type"))
+}
+
+func TestRegisterEncoderRemove(t *testing.T) {
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/", binding.HandlerFunc(func() Person { return Person{Name: "John"} }))
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "text/csv")
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	binding.RegisterEncoder("text/csv", func(w io.Writer, v interface{}) error {
+		_, err := io.WriteString(w, "name\n"+v.(Person).Name+"\n")
+		return err
+	})
+	assert.Equal(t, http.StatusOK, serve())
+	binding.RegisterEncoder("text/csv", nil)
+	assert.Equal(t, http.StatusNotAcceptable, serve())
+
+	assert.Panics(t, func() { binding.RegisterEncoder("application/json", nil) })
+}
+
+func TestHandlerFuncNegotiateEncodeError(t *testing.T) {
+	registerXML(t)
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/", binding.HandlerFunc(func() map[string]string {
+		return map[string]string{"name": "John"}
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	var status metav1.Status
+	if assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &status), w.Body.String()) {
+		assert.Equal(t, metav1.StatusReasonInternalError, status.Reason)
+		assert.Contains(t, status.Message, "failed to encode map[string]string as application/xml")
+	}
+}
+
+type personList []Person
+
+func (l personList) ConvertToTable(_ context.Context, opts *metav1.TableOptions) (*metav1.Table, error) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Email", Type: "string"},
+		},
+	}
+	for _, p := range l {
+		row := metav1.TableRow{Cells: []interface{}{p.Name, p.Email}}
+		if opts.IncludeObject == metav1.IncludeObject {
+			row.Object.Object = &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: p.Name}}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+func TestHandlerFuncTable(t *testing.T) {
+	binding.RegisterTableConvertor(func(_ context.Context, p Person, _ *metav1.TableOptions) (*metav1.Table, error) {
+		return &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}},
+			Rows:              []metav1.TableRow{{Cells: []interface{}{p.Name}}},
+		}, nil
+	})
+	t.Cleanup(func() { binding.RegisterTableConvertor[Person](nil) })
+
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/people", binding.HandlerFunc(func() personList {
+		return personList{{Name: "John", Email: "john@example.com"}, {Name: "Jane"}}
+	}))
+	m.Get("/people/john", binding.HandlerFunc(func() Person { return Person{Name: "John"} }))
+	m.Get("/blogs", binding.HandlerFunc(func() []BlogPost { return nil }))
+
+	const asTable = "application/json;as=Table;v=v1;g=meta.k8s.io"
+	tests := []struct {
+		name   string
+		url    string
+		accept string
+		code   int
+		body   string
+	}{
+		{
+			name:   "list",
+			url:    "/people",
+			accept: asTable + ",application/json",
+			code:   http.StatusOK,
+			body:   `{"kind":"Table","apiVersion":"meta.k8s.io/v1","metadata":{},"columnDefinitions":[{"name":"Name","type":"string","format":"name","description":"","priority":0},{"name":"Email","type":"string","format":"","description":"","priority":0}],"rows":[{"cells":["John","john@example.com"],"object":null},{"cells":["Jane",""],"object":null}]}`,
+		},
+		{
+			name:   "list including objects",
+			url:    "/people?includeObject=Object",
+			accept: asTable,
+			code:   http.StatusOK,
+			body:   `{"kind":"Table","apiVersion":"meta.k8s.io/v1","metadata":{},"columnDefinitions":[{"name":"Name","type":"string","format":"name","description":"","priority":0},{"name":"Email","type":"string","format":"","description":"","priority":0}],"rows":[{"cells":["John","john@example.com"],"object":{"metadata":{"name":"John","creationTimestamp":null}}},{"cells":["Jane",""],"object":{"metadata":{"name":"Jane","creationTimestamp":null}}}]}`,
+		},
+		{
+			name:   "registered convertor",
+			url:    "/people/john",
+			accept: asTable,
+			code:   http.StatusOK,
+			body:   `{"kind":"Table","apiVersion":"meta.k8s.io/v1","metadata":{},"columnDefinitions":[{"name":"Name","type":"string","format":"","description":"","priority":0}],"rows":[{"cells":["John"],"object":null}]}`,
+		},
+		{
+			name:   "table not requested",
+			url:    "/people/john",
+			accept: "application/json",
+			code:   http.StatusOK,
+			body:   `{"name":"John"}`,
+		},
+		{
+			name:   "fallback without convertor",
+			url:    "/blogs",
+			accept: asTable + ",application/json",
+			code:   http.StatusOK,
+			body:   `null`,
+		},
+		{
+			name:   "no convertor",
+			url:    "/blogs",
+			accept: asTable,
+			code:   http.StatusNotAcceptable,
+		},
+		{
+			name:   "unknown group",
+			url:    "/people",
+			accept: "application/json;as=Table;v=v1;g=example.com",
+			code:   http.StatusNotAcceptable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
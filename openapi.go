@@ -0,0 +1,326 @@
+package binding
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"go.wandrs.dev/binding/openapi"
+
+	"github.com/go-chi/chi/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+var (
+	readerType         = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	watchInterfaceType = reflect.TypeOf((*watch.Interface)(nil)).Elem()
+	responderType      = reflect.TypeOf((*responder)(nil)).Elem()
+	statusCoderType    = reflect.TypeOf((*StatusCoder)(nil)).Elem()
+	statusType         = reflect.TypeOf(metav1.Status{})
+)
+
+// OpenAPI walks the routes of r and describes every HandlerFunc route in an
+// OpenAPI 3.1 document:
+//   - the models of the Bind, Form, MultipartForm and JSON middleware on the route
+//     become request bodies for POST, PUT and PATCH and query parameters otherwise,
+//     named after the same json or form tags the binders decode with; the JSON
+//     middleware also decodes the query, so its fields are optional query
+//     parameters next to the body
+//   - the ListOptions middleware adds the Kubernetes list query parameters
+//   - the return type of the HandlerFunc becomes the response schema, in every
+//     media type registered with RegisterEncoder; a Response or StatusCoder is
+//     described as 2XX, as its status code is only known at runtime
+//   - errors are described as metav1.Status
+//
+// Schemas follow the validate tags of the models like those of SchemaOf.
+func OpenAPI(r chi.Routes, info openapi.Info) (*openapi.Document, error) {
+	doc := &openapi.Document{
+		OpenAPI:           openapi.Version,
+		Info:              info,
+		JSONSchemaDialect: openapi.Dialect,
+		Paths:             map[string]*openapi.PathItem{},
+	}
+	g := newSchemaGenerator("json", componentsPrefix)
+	operationIDs := map[string]bool{}
+
+	err := chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		plan := planOf(handler)
+		if plan == nil {
+			return nil // not a HandlerFunc
+		}
+
+		path, params := pathParameters(route)
+		op := &openapi.Operation{
+			Parameters: params,
+			Responses:  g.responses(plan),
+		}
+		if id := operationID(plan); id != "" && !operationIDs[id] {
+			operationIDs[id] = true
+			op.OperationID = id
+		}
+		for _, mw := range middlewares {
+			h, ok := mw(handler).(*injectingHandler)
+			if !ok {
+				continue
+			}
+			if h.model != nil {
+				g.describeModel(op, method, h.model)
+			}
+			for _, t := range h.types {
+				if t == listOptionsType {
+					addParameters(op, listOptionsParameters()...)
+				}
+			}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		switch method {
+		case http.MethodGet:
+			item.Get = op
+		case http.MethodPut:
+			item.Put = op
+		case http.MethodPost:
+			item.Post = op
+		case http.MethodDelete:
+			item.Delete = op
+		case http.MethodOptions:
+			item.Options = op
+		case http.MethodHead:
+			item.Head = op
+		case http.MethodPatch:
+			item.Patch = op
+		case http.MethodTrace:
+			item.Trace = op
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(g.defs) > 0 {
+		doc.Components = &openapi.Components{Schemas: g.defs}
+	}
+	return doc, nil
+}
+
+var routeParam = regexp.MustCompile(`\{([^}:]+)(?::([^}]+))?\}`)
+
+// pathParameters converts a chi route pattern to an OpenAPI path template and
+// returns its parameters. Regular expressions in the pattern become the
+// pattern of the parameter schema.
+func pathParameters(route string) (string, []*openapi.Parameter) {
+	var params []*openapi.Parameter
+	for _, m := range routeParam.FindAllStringSubmatch(route, -1) {
+		params = append(params, &openapi.Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &openapi.Schema{Type: "string", Pattern: m[2]},
+		})
+	}
+	return routeParam.ReplaceAllString(route, "{$1}"), params
+}
+
+// operationID returns the name of a named handler function, or empty for a
+// function literal.
+func operationID(plan *handlerPlan) string {
+	name := runtime.FuncForPC(plan.fn.Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if _, fn, ok := strings.Cut(name, "."); ok {
+		name = fn
+	}
+	if strings.Contains(name, ".func") || strings.HasSuffix(name, "-fm") {
+		return ""
+	}
+	return name
+}
+
+// describeModel adds the model bound by the middleware of plan to op.
+func (g *schemaGenerator) describeModel(op *openapi.Operation, method string, plan *bindingPlan) {
+	hasBody := method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+	form := newSchemaGenerator("form", "")
+
+	switch plan.binder {
+	case binderJSON:
+		params := queryParameters(newSchemaGenerator("json", ""), plan.typ, "")
+		if hasBody {
+			setRequestBody(op, "application/json", g.schema(plan.typ))
+			// bindJSON decodes the query before the body, which may omit them.
+			for _, p := range params {
+				p.Required = false
+			}
+		}
+		addParameters(op, params...)
+	case binderForm:
+		if hasBody {
+			setRequestBody(op, "application/x-www-form-urlencoded", form.schema(plan.typ))
+		} else {
+			addParameters(op, queryParameters(form, plan.typ, "")...)
+		}
+	case binderMultipartForm:
+		setRequestBody(op, "multipart/form-data", form.schema(plan.typ))
+	case binderBind:
+		if hasBody {
+			setRequestBody(op, "application/json", g.schema(plan.typ))
+			setRequestBody(op, "application/x-www-form-urlencoded", form.schema(plan.typ))
+			setRequestBody(op, "multipart/form-data", form.schema(plan.typ))
+		} else {
+			addParameters(op, queryParameters(form, plan.typ, "")...)
+		}
+	}
+}
+
+func setRequestBody(op *openapi.Operation, mediaType string, schema *openapi.Schema) {
+	if op.RequestBody == nil {
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]*openapi.MediaType{}}
+	}
+	op.RequestBody.Content[mediaType] = &openapi.MediaType{Schema: schema}
+}
+
+// queryParameters describes the fields of struct type t as query parameters.
+// Fields of nested structs are named with dots, the way go-playground/form
+// decodes them.
+func queryParameters(g *schemaGenerator, t reflect.Type, prefix string) []*openapi.Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []*openapi.Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f, g.tag)
+		if !ok {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if name == "" {
+			if ft.Kind() == reflect.Struct {
+				params = append(params, queryParameters(g, ft, prefix)...)
+				continue
+			}
+			name = f.Name
+		}
+		if _, known := knownSchemas[ft]; ft.Kind() == reflect.Struct && !known {
+			params = append(params, queryParameters(g, ft, prefix+name+".")...)
+			continue
+		}
+		params = append(params, &openapi.Parameter{
+			Name:     prefix + name,
+			In:       "query",
+			Required: isRequired(f),
+			Schema:   g.constrain(g.schema(f.Type), f.Type, strings.Split(f.Tag.Get("validate"), ",")),
+		})
+	}
+	return params
+}
+
+// listOptionsParameters describes the query parameters bound by ListOptions.
+func listOptionsParameters() []*openapi.Parameter {
+	param := func(name, typ string) *openapi.Parameter {
+		return &openapi.Parameter{Name: name, In: "query", Schema: &openapi.Schema{Type: typ}}
+	}
+	return []*openapi.Parameter{
+		param("labelSelector", "string"),
+		param("fieldSelector", "string"),
+		param("limit", "integer"),
+		param("continue", "string"),
+		param("resourceVersion", "string"),
+		param("resourceVersionMatch", "string"),
+		param("watch", "boolean"),
+		param("allowWatchBookmarks", "boolean"),
+		param("timeoutSeconds", "integer"),
+	}
+}
+
+// addParameters adds params to op unless a parameter of the same name and
+// location is already there.
+func addParameters(op *openapi.Operation, params ...*openapi.Parameter) {
+next:
+	for _, p := range params {
+		for _, existing := range op.Parameters {
+			if existing.Name == p.Name && existing.In == p.In {
+				continue next
+			}
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+}
+
+// responses describes what the function of plan writes on success and the
+// metav1.Status written on errors. Results that are a Response or a
+// StatusCoder choose their status code at runtime and are described as 2XX.
+func (g *schemaGenerator) responses(plan *handlerPlan) map[string]*openapi.Response {
+	responses := map[string]*openapi.Response{
+		"default": {Description: "Error", Content: g.encoded(statusType)},
+	}
+	switch plan.kind {
+	case returnsValue, returnsValueAndError:
+		t := plan.typ.Out(0)
+		if t.Implements(responderType) || t.Implements(statusCoderType) {
+			responses["2XX"] = &openapi.Response{
+				Description: "Success, with the status code set by the returned value at runtime",
+				Content:     g.content(t),
+			}
+			break
+		}
+		responses["200"] = &openapi.Response{Description: "OK", Content: g.content(t)}
+	default:
+		responses["200"] = &openapi.Response{Description: "OK"}
+	}
+	return responses
+}
+
+// content describes how writeResult writes values of type t.
+func (g *schemaGenerator) content(t reflect.Type) map[string]*openapi.MediaType {
+	if t.Implements(responderType) && t.Kind() == reflect.Struct {
+		if body, ok := t.FieldByName("Body"); ok {
+			t = body.Type
+		}
+	}
+
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8,
+		t == readerType || (t.Kind() != reflect.Interface && t.Implements(readerType)):
+		return map[string]*openapi.MediaType{"application/octet-stream": {Schema: binarySchema()}}
+	case t == watchInterfaceType || (t.Kind() != reflect.Interface && t.Implements(watchInterfaceType)) || isWatchChan(t):
+		return map[string]*openapi.MediaType{jsonMediaType: {Schema: g.schema(reflect.TypeOf(metav1.WatchEvent{}))}}
+	case isEventStream(t):
+		return map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}}
+	case isStream(t):
+		elem := t.Elem()
+		if t.Kind() == reflect.Func {
+			elem = t.In(0).In(0)
+		}
+		return map[string]*openapi.MediaType{
+			jsonMediaType:     {Schema: &openapi.Schema{Type: "array", Items: g.schema(elem)}},
+			ndjsonContentType: {Schema: g.schema(elem)},
+		}
+	}
+	return g.encoded(t)
+}
+
+// encoded describes t in every media type registered with RegisterEncoder.
+func (g *schemaGenerator) encoded(t reflect.Type) map[string]*openapi.MediaType {
+	schema := g.schema(t)
+	content := make(map[string]*openapi.MediaType, len(encoders))
+	for _, enc := range encoders {
+		content[enc.mediaType] = &openapi.MediaType{Schema: schema}
+	}
+	return content
+}
@@ -0,0 +1,112 @@
+// Package openapi defines the parts of an OpenAPI 3.1 document that
+// binding.OpenAPI generates. Schemas are JSON Schema draft 2020-12, which is
+// the schema dialect of OpenAPI 3.1.
+package openapi
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Dialect is the JSON Schema dialect of generated schemas.
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Document is the root of an OpenAPI document.
+type Document struct {
+	OpenAPI           string               `json:"openapi"`
+	Info              Info                 `json:"info"`
+	JSONSchemaDialect string               `json:"jsonSchemaDialect,omitempty"`
+	Paths             map[string]*PathItem `json:"paths"`
+	Components        *Components          `json:"components,omitempty"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path.
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+	Trace   *Operation `json:"trace,omitempty"`
+}
+
+// Operation describes a single method on a path.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a path or query parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Style    string  `json:"style,omitempty"`
+	Explode  *bool   `json:"explode,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes the body of a request by media type.
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// MediaType holds the schema of a request or response body.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response describes a response by media type.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Components holds the schemas referenced from the rest of the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is a JSON Schema draft 2020-12 schema.
+type Schema struct {
+	SchemaURI string             `json:"$schema,omitempty"`
+	Ref       string             `json:"$ref,omitempty"`
+	Defs      map[string]*Schema `json:"$defs,omitempty"`
+
+	Type    string        `json:"type,omitempty"`
+	Format  string        `json:"format,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+	Const   interface{}   `json:"const,omitempty"`
+	Pattern string        `json:"pattern,omitempty"`
+	AnyOf   []*Schema     `json:"anyOf,omitempty"`
+	Not     *Schema       `json:"not,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int     `json:"minLength,omitempty"`
+	MaxLength        *int     `json:"maxLength,omitempty"`
+	MinItems         *int     `json:"minItems,omitempty"`
+	MaxItems         *int     `json:"maxItems,omitempty"`
+	MinProperties    *int     `json:"minProperties,omitempty"`
+	MaxProperties    *int     `json:"maxProperties,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	ContentEncoding  string `json:"contentEncoding,omitempty"`
+	ContentMediaType string `json:"contentMediaType,omitempty"`
+}
@@ -0,0 +1,100 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/binding/openapi"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func listPosts(opts metav1.ListOptions) ([]Post, error) {
+	return nil, nil
+}
+
+func TestOpenAPI(t *testing.T) {
+	one := 1
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.With(binding.ListOptions()).Get("/posts", binding.HandlerFunc(listPosts))
+	m.With(binding.JSON(BlogPost{})).Post("/posts", binding.HandlerFunc(func(post BlogPost) (binding.Response[BlogPost], error) {
+		return binding.Response[BlogPost]{Status: http.StatusCreated, Body: post}, nil
+	}))
+	m.With(binding.Form(Person{})).Get("/people/{id:[0-9]+}", binding.HandlerFunc(func(p Person) <-chan Person { return nil }))
+	m.With(binding.MultipartForm(Person{})).Put("/people/{id}", binding.HandlerFunc(func(p Person) error { return nil }))
+	m.Get("/ignored", func(w http.ResponseWriter, r *http.Request) {})
+
+	doc, err := binding.OpenAPI(m, openapi.Info{Title: "blog", Version: "v1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+	assert.Len(t, doc.Paths, 2)
+
+	list := doc.Paths["/posts"].Get
+	require.NotNil(t, list)
+	assert.Equal(t, "listPosts", list.OperationID)
+	assert.Equal(t, "labelSelector", list.Parameters[0].Name)
+	assert.Equal(t, &openapi.Schema{Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/Post"}}, list.Responses["200"].Content["application/json"].Schema)
+	assert.Equal(t, "#/components/schemas/Status", list.Responses["default"].Content["application/json"].Schema.Ref)
+
+	create := doc.Paths["/posts"].Post
+	require.NotNil(t, create)
+	assert.Equal(t, "#/components/schemas/BlogPost", create.RequestBody.Content["application/json"].Schema.Ref)
+	assert.NotContains(t, create.Responses, "200")
+	assert.Contains(t, create.Responses["2XX"].Description, "runtime")
+	assert.Equal(t, "#/components/schemas/BlogPost", create.Responses["2XX"].Content["application/json"].Schema.Ref)
+	names := map[string]bool{}
+	for _, p := range create.Parameters {
+		assert.Equal(t, "query", p.In)
+		assert.False(t, p.Required, p.Name)
+		names[p.Name] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"title": true, "content": true, "Id": true, "ratings": true,
+		"author.name": true, "author.email": true, "coauthor.name": true, "coauthor.email": true,
+	}, names)
+
+	get := doc.Paths["/people/{id}"].Get
+	require.NotNil(t, get)
+	assert.Equal(t, []*openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string", Pattern: "[0-9]+"}},
+		{Name: "name", In: "query", Required: true, Schema: &openapi.Schema{Type: "string", MinLength: &one}},
+		{Name: "email", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	}, get.Parameters)
+	assert.Equal(t, &openapi.Schema{Ref: "#/components/schemas/Person"}, get.Responses["200"].Content["application/x-ndjson"].Schema)
+
+	put := doc.Paths["/people/{id}"].Put
+	require.NotNil(t, put)
+	assert.Equal(t, &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"name":  {Type: "string", MinLength: &one},
+			"email": {Type: "string"},
+		},
+		Required: []string{"name"},
+	}, put.RequestBody.Content["multipart/form-data"].Schema)
+
+	blogPost, err := json.Marshal(doc.Components.Schemas["BlogPost"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string", "minLength": 1},
+			"content": {"type": "string"},
+			"Id": {"type": "integer", "format": "int64", "not": {"const": 0}},
+			"ratings": {"anyOf": [{"type": "null"}, {"type": "array", "items": {"type": "integer", "format": "int64"}}]},
+			"author": {"$ref": "#/components/schemas/Person"},
+			"coauthor": {"anyOf": [{"type": "null"}, {"$ref": "#/components/schemas/Person"}]}
+		},
+		"required": ["title", "Id"]
+	}`, string(blogPost))
+	assert.Contains(t, doc.Components.Schemas, "Status")
+	assert.Contains(t, doc.Components.Schemas, "StatusDetails")
+}
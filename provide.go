@@ -0,0 +1,145 @@
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"go.wandrs.dev/inject"
+)
+
+// provider returns the provider registered for t, falling back to the scope of
+// the parent router. Like the injector, an interface type is satisfied by a
+// provider of any type implementing it.
+func (sc *scope) provider(t reflect.Type) *provider {
+	for ; sc != nil; sc = sc.parent {
+		if p, ok := sc.providers[t]; ok {
+			return p
+		}
+		if t.Kind() == reflect.Interface {
+			for pt, p := range sc.providers {
+				if pt.Implements(t) {
+					return p
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// provider is a constructor registered with Provide.
+type provider struct {
+	fn      reflect.Value
+	fnType  reflect.Type
+	typ     reflect.Type
+	in      []reflect.Type
+	withErr bool
+}
+
+func newProvider(fn interface{}) *provider {
+	typ := reflect.TypeOf(fn)
+	if typ.Kind() != reflect.Func {
+		panic(fmt.Sprintf("fn %s must be a function, found %s", typ, typ.Kind()))
+	}
+
+	p := &provider{
+		fn:     reflect.ValueOf(fn),
+		fnType: typ,
+		in:     make([]reflect.Type, typ.NumIn()),
+	}
+	for i := range p.in {
+		p.in[i] = typ.In(i)
+	}
+
+	switch typ.NumOut() {
+	case 1:
+	case 2:
+		if !typ.Out(1).Implements(errorType) {
+			panic(fmt.Sprintf("fn %s 2nd return value must implement error", typ))
+		}
+		p.withErr = true
+	default:
+		panic(fmt.Sprintf("fn %s must return a value and optionally an error", typ))
+	}
+	p.typ = typ.Out(0)
+	if p.typ.Implements(errorType) {
+		panic(fmt.Sprintf("fn %s 1st return value must not an error", typ))
+	}
+	return p
+}
+
+// invoke constructs the value of the provider and memoizes it in the injector
+// for the rest of the request.
+func (p *provider) invoke(injector inject.Injector, sc *scope) (reflect.Value, error) {
+	for _, r := range sc.resolving {
+		if r == p {
+			panic(fmt.Sprintf("fn %s depends on its own return value", p.fnType))
+		}
+	}
+	sc.resolving = append(sc.resolving, p)
+	defer func() {
+		sc.resolving = sc.resolving[:len(sc.resolving)-1]
+	}()
+
+	in := make([]reflect.Value, len(p.in))
+	for i, t := range p.in {
+		val, err := resolve(injector, t)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !val.IsValid() {
+			panic(fmt.Sprintf("failed to invoke %s, reason: value not found for type %v", p.fnType, t))
+		}
+		in[i] = val
+	}
+
+	results := p.fn.Call(in)
+	if p.withErr {
+		// WARNING: https://stackoverflow.com/a/46275411/244009
+		if err, _ := results[1].Interface().(error); err != nil && !results[1].IsNil() {
+			return reflect.Value{}, err
+		}
+	}
+	injector.Set(p.typ, results[0])
+	sc.track(results[0].Interface())
+	return results[0], nil
+}
+
+// resolve returns the value of type t for the current request. If nothing of
+// type t has been injected, a provider registered with Provide is invoked.
+// The returned value is invalid if t can not be resolved at all.
+func resolve(injector inject.Injector, t reflect.Type) (reflect.Value, error) {
+	if val := injector.GetVal(t); val.IsValid() {
+		return val, nil
+	}
+	sc := scopeOf(injector)
+	if sc == nil {
+		return reflect.Value{}, nil
+	}
+	if p := sc.provider(t); p != nil {
+		return p.invoke(injector, sc)
+	}
+	return reflect.Value{}, nil
+}
+
+// Provide registers fn as a lazy constructor for the current request.
+// fn may take any injected values as arguments, including the values of other
+// providers, and must return either some_value or (some_value, error).
+// It is only invoked when a HandlerFunc or another provider asks for the type of
+// some_value, and at most once per request. A returned error is converted to
+// metav1.Status and written to http.ResponseWriter as a JSON object.
+// If some_value implements io.Closer, it is closed when the request finishes.
+func Provide(fn interface{}) func(next http.Handler) http.Handler {
+	p := newProvider(fn)
+	return func(next http.Handler) http.Handler {
+		return &injectingHandler{types: []reflect.Type{p.typ}, provider: p, HandlerFunc: func(w http.ResponseWriter, req *http.Request) {
+			injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
+			if injector == nil {
+				panic("chi: register Injector middleware")
+			}
+
+			scopeOf(injector).providers[p.typ] = p
+			next.ServeHTTP(w, req)
+		}}
+	}
+}
@@ -0,0 +1,121 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type user struct {
+	Name string
+}
+
+type session struct {
+	User *user
+}
+
+func TestProvide(t *testing.T) {
+	var calls int
+	newUser := func(req *http.Request) (*user, error) {
+		calls++
+		name := req.URL.Query().Get("user")
+		if name == "" {
+			return nil, apierrors.NewUnauthorized("missing user")
+		}
+		return &user{Name: name}, nil
+	}
+	newSession := func(u *user) session {
+		return session{User: u}
+	}
+
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Use(binding.Provide(newUser))
+	m.Use(binding.Provide(newSession))
+	m.Get("/lazy", binding.HandlerFunc(func() string { return "lazy" }))
+	m.Get("/user", binding.HandlerFunc(func(u *user, s session) string {
+		assert.Same(t, u, s.User)
+		return u.Name
+	}))
+	m.Get("/model", binding.HandlerFunc(func(mdl modeler) string { return mdl.Model() }))
+	m.With(binding.Provide(func(s session) Post { return Post{Title: s.User.Name} })).
+		Get("/post", binding.HandlerFunc(func(mdl modeler) string { return mdl.Model() }))
+
+	tests := []struct {
+		name  string
+		url   string
+		code  int
+		body  string
+		calls int
+	}{
+		{name: "not asked for", url: "/lazy", code: http.StatusOK, body: `"lazy"`, calls: 0},
+		{name: "memoized", url: "/user?user=john", code: http.StatusOK, body: `"john"`, calls: 1},
+		{name: "interface", url: "/post?user=jane", code: http.StatusOK, body: `"jane"`, calls: 1},
+		{name: "error", url: "/user", code: http.StatusUnauthorized, calls: 1},
+		{name: "not provided", url: "/model", code: http.StatusInternalServerError, calls: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			resp := w.Result()
+			assert.Equal(t, tt.code, resp.StatusCode)
+			assert.Equal(t, tt.calls, calls)
+			if tt.code == http.StatusOK {
+				assert.Equal(t, tt.body, w.Body.String())
+			} else {
+				var status metav1.Status
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+				assert.EqualValues(t, tt.code, status.Code)
+			}
+		})
+	}
+}
+
+func TestProvideVerify(t *testing.T) {
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Use(binding.Provide(func() (schema.GroupKind, error) { return schema.GroupKind{}, nil }))
+	m.Get("/", binding.HandlerFunc(func(gk schema.GroupKind) string { return gk.String() }))
+
+	assert.NoError(t, binding.Verify(m))
+}
+
+func TestProvideVerifyDependencies(t *testing.T) {
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Use(binding.Provide(func(p Person) *user { return &user{Name: p.Name} }))
+	m.Use(binding.Provide(func(u *user) session { return session{User: u} }))
+	m.Get("/session", binding.HandlerFunc(func(s session) string { return s.User.Name }))
+	m.With(binding.Map(Person{Name: "Matt Holt"})).
+		Get("/person", binding.HandlerFunc(func(s session) string { return s.User.Name }))
+	m.With(binding.Provide(func(s session) clock { return fixedClock(s.User.Name) })).
+		Get("/clock", binding.HandlerFunc(func(c clock) string { return c.Now() }))
+
+	err := binding.Verify(m)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "GET /session: fn func(binding_test.Person) *binding_test.user has unresolved dependency binding_test.Person")
+		assert.Contains(t, err.Error(), "GET /clock: fn func(binding_test.Person) *binding_test.user has unresolved dependency binding_test.Person")
+		assert.NotContains(t, err.Error(), "GET /person")
+	}
+}
+
+func TestProvideSignature(t *testing.T) {
+	assert.Panics(t, func() { binding.Provide("not a func") })
+	assert.Panics(t, func() { binding.Provide(func() {}) })
+	assert.Panics(t, func() { binding.Provide(func() (string, int) { return "", 0 }) })
+	assert.Panics(t, func() { binding.Provide(func() error { return nil }) })
+}
@@ -0,0 +1,114 @@
+package binding
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+
+	"go.wandrs.dev/inject"
+
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// StatusCoder is implemented by values returned from a HandlerFunc that are
+// written with a status code other than http.StatusOK.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Headerer is implemented by values returned from a HandlerFunc that add
+// headers like Location or ETag to the response.
+type Headerer interface {
+	Header() http.Header
+}
+
+// Response can be returned from a HandlerFunc to write Body with an explicit
+// status code and headers. A zero Status means http.StatusOK.
+//
+//	func createPost(post Post) (binding.Response[Post], error) {
+//	    ...
+//	    return binding.Response[Post]{
+//	        Status: http.StatusCreated,
+//	        Header: http.Header{"Location": {"/posts/" + post.ID}},
+//	        Body:   post,
+//	    }, nil
+//	}
+type Response[T any] struct {
+	Status int
+	Header http.Header
+	Body   T
+}
+
+// responder is implemented by every instance of Response.
+type responder interface {
+	response() (int, http.Header, interface{})
+}
+
+func (r Response[T]) response() (int, http.Header, interface{}) {
+	return r.Status, r.Header, r.Body
+}
+
+// writeResult writes a value returned from a HandlerFunc. []byte is written
+// directly, io.Reader is copied, watch.Interface is written as watch events,
+// channels and iterators are streamed and any other value is encoded in the
+// media type negotiated from the Accept header.
+func writeResult(w http.ResponseWriter, req *http.Request, injector inject.Injector, v reflect.Value) {
+	body := v.Interface()
+	status := http.StatusOK
+
+	var header http.Header
+	if r, ok := body.(responder); ok && !isNil(body) {
+		status, header, body = r.response()
+	} else if !isNil(body) {
+		if sc, ok := body.(StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+		if h, ok := body.(Headerer); ok {
+			header = h.Header()
+		}
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	for k, vals := range header {
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	if !isNil(body) {
+		if wi, ok := body.(watch.Interface); ok {
+			writeWatch(w, req, injector, status, wi.ResultChan(), wi.Stop)
+			return
+		}
+		if r, ok := body.(io.Reader); ok {
+			if err := writeReader(w, req, status, r); err != nil {
+				writeError(injector, err)
+			}
+			return
+		}
+		if bv := reflect.ValueOf(body); isWatchChan(bv.Type()) {
+			ch := bv.Convert(reflect.TypeOf((<-chan watch.Event)(nil))).Interface().(<-chan watch.Event)
+			writeWatch(w, req, injector, status, ch, nil)
+			return
+		} else if isEventStream(bv.Type()) {
+			writeEvents(w, req, status, bv)
+			return
+		} else if isStream(bv.Type()) {
+			writeStream(w, req, status, bv)
+			return
+		}
+	}
+	if bv := reflect.ValueOf(body); bv.Kind() == reflect.Slice && bv.Type().Elem().Kind() == reflect.Uint8 {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+		}
+		_, _ = w.Write(bv.Bytes())
+		return
+	}
+	writeValue(w, req, injector, status, body)
+}
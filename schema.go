@@ -0,0 +1,438 @@
+package binding
+
+import (
+	"encoding"
+	gojson "encoding/json"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.wandrs.dev/binding/openapi"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*gojson.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// knownSchemas describe types that are not encoded the way their Go
+	// definition suggests.
+	knownSchemas = map[reflect.Type]func() *openapi.Schema{
+		reflect.TypeOf(time.Time{}):                dateTimeSchema,
+		reflect.TypeOf(metav1.Time{}):              dateTimeSchema,
+		reflect.TypeOf(metav1.MicroTime{}):         dateTimeSchema,
+		reflect.TypeOf(metav1.Duration{}):          func() *openapi.Schema { return &openapi.Schema{Type: "string"} },
+		reflect.TypeOf(multipart.FileHeader{}):     binarySchema,
+		reflect.TypeOf(time.Duration(0)):           func() *openapi.Schema { return &openapi.Schema{Type: "integer", Format: "int64"} },
+		reflect.TypeOf((*interface{})(nil)).Elem(): func() *openapi.Schema { return &openapi.Schema{} },
+	}
+)
+
+func dateTimeSchema() *openapi.Schema {
+	return &openapi.Schema{Type: "string", Format: "date-time"}
+}
+
+func binarySchema() *openapi.Schema {
+	return &openapi.Schema{Type: "string", ContentMediaType: "application/octet-stream"}
+}
+
+// schemaGenerator converts Go types to JSON Schema the way the binders and
+// encoders of this package see them. Field names are read from tag, which is
+// json for JSON bodies and responses and form for form values. With a non-empty
+// refPrefix, named struct types are put in defs once and referenced.
+type schemaGenerator struct {
+	tag       string
+	refPrefix string
+	defs      map[string]*openapi.Schema
+	names     map[reflect.Type]string
+	visiting  map[reflect.Type]bool
+}
+
+func newSchemaGenerator(tag, refPrefix string) *schemaGenerator {
+	return &schemaGenerator{
+		tag:       tag,
+		refPrefix: refPrefix,
+		defs:      map[string]*openapi.Schema{},
+		names:     map[reflect.Type]string{},
+		visiting:  map[reflect.Type]bool{},
+	}
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *openapi.Schema {
+	if known, ok := knownSchemas[t]; ok {
+		return known()
+	}
+	if t.Kind() == reflect.Ptr {
+		return g.schema(t.Elem())
+	}
+	if g.tag == "json" && (t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType)) {
+		return &openapi.Schema{} // encoded in a way only the type itself knows
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &openapi.Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openapi.Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &openapi.Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &openapi.Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openapi.Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openapi.Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openapi.Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && g.tag == "json" {
+			return &openapi.Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &openapi.Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &openapi.Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if g.refPrefix == "" || t.Name() == "" {
+			if g.visiting[t] {
+				return &openapi.Schema{Type: "object"} // recursive type without references
+			}
+			g.visiting[t] = true
+			defer delete(g.visiting, t)
+			return g.object(t)
+		}
+		return &openapi.Schema{Ref: g.refPrefix + g.define(t)}
+	}
+	return &openapi.Schema{}
+}
+
+// define puts the schema of the named struct type t in defs and returns its name.
+func (g *schemaGenerator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := defName(t.Name())
+	if _, taken := g.defs[name]; taken {
+		name = defName(t.PkgPath() + "." + t.Name())
+	}
+	g.names[t] = name
+	g.defs[name] = nil // reserve the name for recursive references
+	g.defs[name] = g.object(t)
+	return name
+}
+
+var invalidDefName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func defName(name string) string {
+	name = strings.ReplaceAll(name, "/", ".")
+	return strings.Trim(invalidDefName.ReplaceAllString(name, "_"), "_")
+}
+
+// object returns the schema of the fields of struct type t.
+func (g *schemaGenerator) object(t reflect.Type) *openapi.Schema {
+	s := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}}
+	g.fields(t, s)
+	return s
+}
+
+func (g *schemaGenerator) fields(t reflect.Type, s *openapi.Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f, g.tag)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s) // embedded fields are promoted
+				continue
+			}
+			name = f.Name
+		}
+		prop := g.schema(f.Type)
+		if g.tag == "json" && hasOption(f.Tag.Get("json"), "string") {
+			switch prop.Type {
+			case "integer", "number", "boolean":
+				prop = &openapi.Schema{Type: "string"} // encoded as a quoted value
+			}
+		}
+		prop = g.constrain(prop, f.Type, strings.Split(f.Tag.Get("validate"), ","))
+		omitted := g.tag == "json" && hasOption(f.Tag.Get("json"), "omitempty")
+		if g.tag == "json" && !omitted && !isRequired(f) && isNillable(f.Type) {
+			prop = &openapi.Schema{AnyOf: []*openapi.Schema{{Type: "null"}, prop}} // nil is encoded as null
+		}
+		s.Properties[name] = prop
+		if isRequired(f) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// fieldName returns the name of f under tag, empty for an embedded struct whose
+// fields are promoted, and false if f is not encoded at all.
+func fieldName(f reflect.StructField, tag string) (string, bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" && !f.Anonymous {
+		name = f.Name
+	}
+	return name, true
+}
+
+// validateTags returns the validate tags of f that apply to f itself rather
+// than to the elements of a slice or map.
+func validateTags(f reflect.StructField) []string {
+	tags := strings.Split(f.Tag.Get("validate"), ",")
+	for i, tag := range tags {
+		if tag == "dive" {
+			return tags[:i]
+		}
+	}
+	return tags
+}
+
+func isRequired(f reflect.StructField) bool {
+	for _, tag := range validateTags(f) {
+		if tag == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// isNillable reports whether values of type t can be nil.
+func isNillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func hasOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// constrain translates the validate tags of a value of type t to keywords of
+// its schema s. Tags after dive apply to the elements of a slice or map. With
+// omitempty, the zero value of t is valid too, as the validator skips it.
+func (g *schemaGenerator) constrain(s *openapi.Schema, t reflect.Type, tags []string) *openapi.Schema {
+	isPtr := t.Kind() == reflect.Ptr
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var omitEmpty, constrained bool
+	for i := 0; i < len(tags); i++ {
+		name, param, _ := strings.Cut(tags[i], "=")
+		if strings.Contains(name, "|") {
+			continue // alternatives can not be expressed
+		}
+		switch name {
+		case "omitempty":
+			omitEmpty = true
+		case "required":
+			if !isPtr { // a required pointer only must not be nil
+				constrained = requireNonZero(s, t) || constrained
+			}
+		case "keys":
+			for i < len(tags) && tags[i] != "endkeys" {
+				i++
+			}
+		case "dive":
+			rest := tags[i+1:]
+			switch {
+			case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && s.Items != nil:
+				s.Items = g.constrain(s.Items, t.Elem(), rest)
+			case t.Kind() == reflect.Map && s.AdditionalProperties != nil:
+				s.AdditionalProperties = g.constrain(s.AdditionalProperties, t.Elem(), rest)
+			}
+			i = len(tags)
+		case "min", "max", "len", "eq", "gt", "gte", "lt", "lte":
+			constrained = bound(s, t, name, param) || constrained
+		case "oneof":
+			if enum := enumOf(t, param); enum != nil {
+				s.Enum, constrained = enum, true
+			}
+		case "email", "hostname", "ipv4", "ipv6", "uuid":
+			s.Format, constrained = name, true
+		case "uuid3", "uuid4", "uuid5":
+			s.Format, constrained = "uuid", true
+		case "url", "uri":
+			s.Format, constrained = "uri", true
+		}
+	}
+
+	if omitEmpty && constrained {
+		if zero := zeroSchema(t); zero != nil {
+			return &openapi.Schema{AnyOf: []*openapi.Schema{zero, s}}
+		}
+	}
+	return s
+}
+
+// requireNonZero excludes the zero value of t from s, as the required tag
+// rejects it.
+func requireNonZero(s *openapi.Schema, t reflect.Type) bool {
+	switch {
+	case t.Kind() == reflect.String && s.Type == "string":
+		if s.MinLength == nil || *s.MinLength < 1 {
+			one := 1
+			s.MinLength = &one
+		}
+		return true
+	case t.Kind() == reflect.Bool && s.Type == "boolean":
+		s.Const = true
+		return true
+	case s.Type == "integer" || s.Type == "number":
+		s.Not = &openapi.Schema{Const: 0}
+		return true
+	}
+	return false
+}
+
+// bound sets the keyword of the size constraint name=param on s, which limits
+// the length of strings, slices and maps and the value of numbers.
+func bound(s *openapi.Schema, t reflect.Type, name, param string) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if t.Kind() == reflect.String && name == "eq" {
+			s.Const = param
+			return true
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return false
+		}
+		minimum, maximum := &s.MinLength, &s.MaxLength
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			minimum, maximum = &s.MinItems, &s.MaxItems
+		} else if t.Kind() == reflect.Map {
+			minimum, maximum = &s.MinProperties, &s.MaxProperties
+		}
+		switch name {
+		case "min", "gte":
+			*minimum = &n
+		case "gt":
+			n++
+			*minimum = &n
+		case "max", "lte":
+			*maximum = &n
+		case "lt":
+			n--
+			*maximum = &n
+		case "len", "eq":
+			*minimum, *maximum = &n, &n
+		}
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return false // the parameter is a duration
+		}
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false
+		}
+		switch name {
+		case "min", "gte":
+			s.Minimum = &n
+		case "max", "lte":
+			s.Maximum = &n
+		case "gt":
+			s.ExclusiveMinimum = &n
+		case "lt":
+			s.ExclusiveMaximum = &n
+		case "len", "eq":
+			s.Const = n
+		}
+		return true
+	}
+	return false
+}
+
+// enumOf returns the values of a oneof tag parameter as values of type t.
+func enumOf(t reflect.Type, param string) []interface{} {
+	var enum []interface{}
+	for _, v := range strings.Fields(param) {
+		v = strings.Trim(v, "'")
+		switch t.Kind() {
+		case reflect.String:
+			enum = append(enum, v)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil
+			}
+			enum = append(enum, n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return nil
+			}
+			enum = append(enum, n)
+		default:
+			return nil
+		}
+	}
+	return enum
+}
+
+// zeroSchema returns a schema that only the zero value of t matches.
+func zeroSchema(t reflect.Type) *openapi.Schema {
+	zero := 0
+	switch t.Kind() {
+	case reflect.String:
+		return &openapi.Schema{Const: ""}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return &openapi.Schema{Const: 0}
+	case reflect.Slice, reflect.Array:
+		return &openapi.Schema{Type: "array", MaxItems: &zero}
+	case reflect.Map:
+		return &openapi.Schema{Type: "object", MaxProperties: &zero}
+	}
+	return nil
+}
+
+// SchemaOf returns the JSON Schema draft 2020-12 of the JSON encoding of the
+// type of obj, for validating binding models on the client with the same rules
+// as the server. Properties are named after the json tags. The go-playground
+// validate tags required, min, max, len, eq, gt, gte, lt, lte, oneof, email,
+// url, uri, uuid, hostname, ipv4 and ipv6 become the matching keywords, those
+// after dive constrain the elements of slices and maps, and a value that is
+// valid because of omitempty is allowed by an anyOf. Required strings,
+// numbers and booleans must not be zero. Like the encoder writes them,
+// pointers, slices and maps without the omitempty option of their json tag
+// may be null unless they are required. Named struct types are placed in
+// $defs.
+func SchemaOf(obj interface{}) *openapi.Schema {
+	g := newSchemaGenerator("json", "#/$defs/")
+	s := g.schema(reflect.TypeOf(obj))
+	s.SchemaURI = openapi.Dialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
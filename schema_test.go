@@ -0,0 +1,122 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signup struct {
+	Username string            `json:"username" validate:"required,min=3,max=16"`
+	Email    string            `json:"email" validate:"required,email"`
+	Website  string            `json:"website,omitempty" validate:"omitempty,url"`
+	ID       string            `json:"id" validate:"uuid"`
+	Plan     string            `json:"plan" validate:"oneof=free pro"`
+	Age      int               `json:"age,omitempty" validate:"gte=18,lt=130"`
+	Code     int               `json:"code,string"`
+	Tags     []string          `json:"tags" validate:"max=5,dive,len=4"`
+	Labels   map[string]string `json:"labels" validate:"dive,min=1"`
+	Friends  []Person          `json:"friends" validate:"min=1,dive"`
+	Ignored  string            `json:"-"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	data, err := json.Marshal(binding.SchemaOf(signup{}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$ref": "#/$defs/signup",
+		"$defs": {
+			"signup": {
+				"type": "object",
+				"properties": {
+					"username": {"type": "string", "minLength": 3, "maxLength": 16},
+					"email": {"type": "string", "format": "email", "minLength": 1},
+					"website": {"anyOf": [{"const": ""}, {"type": "string", "format": "uri"}]},
+					"id": {"type": "string", "format": "uuid"},
+					"plan": {"type": "string", "enum": ["free", "pro"]},
+					"age": {"type": "integer", "format": "int64", "minimum": 18, "exclusiveMaximum": 130},
+					"code": {"type": "string"},
+					"tags": {"anyOf": [{"type": "null"}, {"type": "array", "maxItems": 5, "items": {"type": "string", "minLength": 4, "maxLength": 4}}]},
+					"labels": {"anyOf": [{"type": "null"}, {"type": "object", "additionalProperties": {"type": "string", "minLength": 1}}]},
+					"friends": {"anyOf": [{"type": "null"}, {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/Person"}}]}
+				},
+				"required": ["username", "email"]
+			},
+			"Person": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"email": {"type": "string"}
+				},
+				"required": ["name"]
+			}
+		}
+	}`, string(data))
+
+	data, err = json.Marshal(binding.SchemaOf([]int{}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"items": {"type": "integer", "format": "int64"}
+	}`, string(data))
+}
+
+func TestSchemaOfEncoding(t *testing.T) {
+	propertyOf := func(t *testing.T, obj interface{}, name string) string {
+		t.Helper()
+		s := binding.SchemaOf(obj)
+		require.Len(t, s.Defs, 1)
+		for _, def := range s.Defs {
+			data, err := json.Marshal(def.Properties[name])
+			require.NoError(t, err)
+			return string(data)
+		}
+		return ""
+	}
+
+	t.Run("required is not zero", func(t *testing.T) {
+		type profile struct {
+			Name     string  `json:"name" validate:"required"`
+			Nickname string  `json:"nickname,omitempty" validate:"required"`
+			Age      int     `json:"age" validate:"required"`
+			Admin    bool    `json:"admin" validate:"required"`
+			Avatar   *string `json:"avatar" validate:"required"`
+		}
+		s := binding.SchemaOf(profile{})
+		assert.Equal(t, []string{"name", "nickname", "age", "admin", "avatar"}, s.Defs["profile"].Required)
+		assert.JSONEq(t, `{"type": "string", "minLength": 1}`, propertyOf(t, profile{}, "nickname"))
+		assert.JSONEq(t, `{"type": "integer", "format": "int64", "not": {"const": 0}}`, propertyOf(t, profile{}, "age"))
+		assert.JSONEq(t, `{"type": "boolean", "const": true}`, propertyOf(t, profile{}, "admin"))
+		assert.JSONEq(t, `{"type": "string"}`, propertyOf(t, profile{}, "avatar"))
+	})
+
+	t.Run("nil is null", func(t *testing.T) {
+		type profile struct {
+			Tags    []string          `json:"tags"`
+			Labels  map[string]string `json:"labels"`
+			Avatar  *string           `json:"avatar"`
+			Aliases []string          `json:"aliases,omitempty"`
+			Emails  []string          `json:"emails" validate:"required"`
+		}
+		assert.JSONEq(t, `{"anyOf": [{"type": "null"}, {"type": "array", "items": {"type": "string"}}]}`, propertyOf(t, profile{}, "tags"))
+		assert.JSONEq(t, `{"anyOf": [{"type": "null"}, {"type": "object", "additionalProperties": {"type": "string"}}]}`, propertyOf(t, profile{}, "labels"))
+		assert.JSONEq(t, `{"anyOf": [{"type": "null"}, {"type": "string"}]}`, propertyOf(t, profile{}, "avatar"))
+		assert.JSONEq(t, `{"type": "array", "items": {"type": "string"}}`, propertyOf(t, profile{}, "aliases"))
+		assert.JSONEq(t, `{"type": "array", "items": {"type": "string"}}`, propertyOf(t, profile{}, "emails"))
+	})
+
+	t.Run("string eq is const", func(t *testing.T) {
+		type profile struct {
+			Role  string `json:"role" validate:"eq=admin"`
+			Level string `json:"level" validate:"eq=3"`
+		}
+		assert.JSONEq(t, `{"type": "string", "const": "admin"}`, propertyOf(t, profile{}, "role"))
+		assert.JSONEq(t, `{"type": "string", "const": "3"}`, propertyOf(t, profile{}, "level"))
+	})
+}
@@ -0,0 +1,121 @@
+package binding
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+
+	"go.wandrs.dev/inject"
+)
+
+// scope is the request scoped state kept next to the values of an injector.
+// It is mapped into its own injector, so it can be found from the injector
+// stored in the request context.
+type scope struct {
+	injector  inject.Injector
+	parent    *scope
+	providers map[reflect.Type]*provider
+	resolving []*provider
+
+	closers  []io.Closer
+	finishes []func(status int, err error)
+	err      error // error written by writeError in this or a child scope, passed to the OnFinish hooks
+}
+
+var scopeType = reflect.TypeOf((*scope)(nil))
+
+func newScope() *scope {
+	return &scope{
+		injector:  inject.New(),
+		providers: make(map[reflect.Type]*provider),
+	}
+}
+
+// reset prepares a pooled scope for a new request. parent is the injector of
+// the parent router, if any.
+func (sc *scope) reset(parent inject.Injector) {
+	sc.injector.Reset()
+	sc.injector.SetParent(parent)
+	sc.parent = nil
+	if parent != nil {
+		sc.parent = scopeOf(parent)
+	}
+	for t := range sc.providers {
+		delete(sc.providers, t)
+	}
+	sc.resolving = sc.resolving[:0]
+	sc.closers = sc.closers[:0]
+	sc.finishes = sc.finishes[:0]
+	sc.err = nil
+	sc.injector.Set(scopeType, reflect.ValueOf(sc))
+}
+
+// track remembers val to be closed when the request finishes, if it is an io.Closer.
+func (sc *scope) track(val interface{}) {
+	if c, ok := val.(io.Closer); ok && !isNil(c) {
+		sc.closers = append(sc.closers, c)
+	}
+}
+
+// finish runs the OnFinish hooks and then closes the tracked values, both in
+// the reverse order they were registered. Errors from Close are ignored.
+func (sc *scope) finish(status int, err error) {
+	if status == 0 {
+		status = http.StatusOK // nothing was written, net/http responds with 200
+	}
+	for i := len(sc.finishes) - 1; i >= 0; i-- {
+		sc.finishes[i](status, err)
+	}
+	for i := len(sc.closers) - 1; i >= 0; i-- {
+		_ = sc.closers[i].Close()
+	}
+}
+
+// done ends the scope when the Injector middleware that created it returns. A
+// child scope hands its OnFinish hooks and tracked values over to its parent,
+// so that they run after the middleware of the parent router unwound as well,
+// with the final status code and error of the request.
+func (sc *scope) done(status int, err error) {
+	if sc.parent != nil {
+		sc.parent.finishes = append(sc.parent.finishes, sc.finishes...)
+		sc.parent.closers = append(sc.parent.closers, sc.closers...)
+		return
+	}
+	sc.finish(status, err)
+}
+
+func scopeOf(injector inject.Injector) *scope {
+	val := injector.GetVal(scopeType)
+	if !val.IsValid() {
+		return nil
+	}
+	return val.Interface().(*scope)
+}
+
+// OnFinish registers fn to run after the handler of req returns, with the status
+// code of the response and the error written as metav1.Status, if any. A panic
+// is reported as a 500 with its value as error. The hooks, and the closing of
+// values of sub routers, run once the Injector of the outermost router
+// returns. It can be used to commit or roll back a transaction created for the
+// request. req must be served under the Injector middleware.
+func OnFinish(req *http.Request, fn func(status int, err error)) {
+	injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
+	if injector == nil {
+		panic("chi: register Injector middleware")
+	}
+	sc := scopeOf(injector)
+	sc.finishes = append(sc.finishes, fn)
+}
+
+// CloseOnFinish registers c to be closed after the OnFinish hooks of req ran,
+// for values created for the request only, like a file or a connection taken
+// from a pool. Values mapped with Map or Inject are never closed, as they may
+// be shared between requests, and values created with Provide are closed
+// without it. req must be served under the Injector middleware.
+func CloseOnFinish(req *http.Request, c io.Closer) {
+	injector, _ := req.Context().Value(injectorKey{}).(inject.Injector)
+	if injector == nil {
+		panic("chi: register Injector middleware")
+	}
+	scopeOf(injector).track(c)
+}
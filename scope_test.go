@@ -0,0 +1,141 @@
+package binding_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	"go.wandrs.dev/inject"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type closer struct {
+	name   string
+	closed *[]string
+}
+
+func (c *closer) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return nil
+}
+
+type tx struct {
+	closer
+}
+
+func TestOnFinish(t *testing.T) {
+	defer func(logger func(*http.Request, interface{}, []byte)) {
+		binding.PanicLogger = logger
+	}(binding.PanicLogger)
+	binding.PanicLogger = func(*http.Request, interface{}, []byte) {}
+
+	var (
+		closed   []string
+		finished []string
+		status   int
+		err      error
+	)
+	shared := &closer{name: "shared", closed: &closed}
+
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Use(binding.Map(shared))
+	m.Use(binding.Inject(func(injector inject.Injector) error {
+		injector.Map(&closer{name: "injected", closed: &closed}) // may be shared, so it is not closed
+		return nil
+	}))
+	m.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			binding.CloseOnFinish(req, &closer{name: "registered", closed: &closed})
+			next.ServeHTTP(w, req)
+		})
+	})
+	m.Use(binding.Provide(func(req *http.Request) *tx {
+		binding.OnFinish(req, func(code int, e error) {
+			finished = append(finished, "tx")
+			status, err = code, e
+		})
+		return &tx{closer{name: "provided", closed: &closed}}
+	}))
+	m.Get("/created", binding.HandlerFunc(func(w http.ResponseWriter, t *tx) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	m.Get("/lazy", binding.HandlerFunc(func() string { return "lazy" }))
+	m.Get("/error", binding.HandlerFunc(func(t *tx) (string, error) {
+		return "", apierrors.NewConflict(schema.GroupResource{Resource: "posts"}, "foo", errors.New("exists"))
+	}))
+	m.Get("/panic", binding.HandlerFunc(func(t *tx) string {
+		panic("boom")
+	}))
+
+	tests := []struct {
+		url      string
+		status   int
+		err      bool
+		closed   []string
+		finished []string
+	}{
+		{url: "/created", status: http.StatusCreated, closed: []string{"provided", "registered"}, finished: []string{"tx"}},
+		{url: "/lazy", status: 0, closed: []string{"registered"}},
+		{url: "/error", status: http.StatusConflict, err: true, closed: []string{"provided", "registered"}, finished: []string{"tx"}},
+		{url: "/panic", status: http.StatusInternalServerError, err: true, closed: []string{"provided", "registered"}, finished: []string{"tx"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			closed, finished, status, err = nil, nil, 0, nil
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.closed, closed)
+			assert.Equal(t, tt.finished, finished)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.err, err != nil)
+		})
+	}
+}
+
+func TestOnFinishSubRouter(t *testing.T) {
+	var (
+		events []string
+		err    error
+	)
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			binding.OnFinish(req, func(code int, e error) {
+				events = append(events, "parent finished")
+				err = e
+			})
+			next.ServeHTTP(w, req)
+			events = append(events, "parent middleware returned")
+		})
+	})
+	m.Route("/posts", func(r chi.Router) {
+		r.Use(binding.Injector(render.New()))
+		r.Use(binding.Provide(func(req *http.Request) *tx {
+			binding.OnFinish(req, func(code int, e error) {
+				events = append(events, "child finished")
+			})
+			return &tx{closer{name: "child closed", closed: &events}}
+		}))
+		r.Get("/", binding.HandlerFunc(func(t *tx) (string, error) {
+			return "", apierrors.NewConflict(schema.GroupResource{Resource: "posts"}, "foo", errors.New("exists"))
+		}))
+	})
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/", nil))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, []string{"parent middleware returned", "child finished", "parent finished", "child closed"}, events)
+	assert.True(t, apierrors.IsConflict(err), "the parent hook sees the error of the sub router: %v", err)
+}
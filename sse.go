@@ -0,0 +1,142 @@
+package binding
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a Server-Sent Event. A HandlerFunc returning <-chan Event streams
+// the events as text/event-stream until the channel is closed or the client
+// goes away.
+type Event struct {
+	// ID is sent back by the browser as LastEventID when it reconnects.
+	ID string
+	// Event is the event type. The browser dispatches untyped events as "message".
+	Event string
+	// Data is written as is if it is a string or []byte, otherwise it is
+	// converted to JSON.
+	Data interface{}
+	// Retry tells the browser how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// LastEventID is the Last-Event-ID header sent by a reconnecting browser.
+// It can be taken as an argument by any HandlerFunc.
+type LastEventID string
+
+// HeartbeatInterval is how often a comment is sent on an idle event stream,
+// so that proxies do not close the connection. Set to 0 to disable heartbeats.
+var HeartbeatInterval = 15 * time.Second
+
+var (
+	eventChanType   = reflect.TypeOf((<-chan Event)(nil))
+	lastEventIDType = reflect.TypeOf(LastEventID(""))
+)
+
+// isEventStream reports whether values of t are written as text/event-stream.
+func isEventStream(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.ChanDir()&reflect.RecvDir != 0 && t.Elem() == eventChanType.Elem()
+}
+
+// writeEvents writes the events received from v, flushing after each event and
+// sending heartbeats while it is idle.
+func writeEvents(w http.ResponseWriter, req *http.Request, status int, v reflect.Value) {
+	events := v.Convert(eventChanType).Interface().(<-chan Event)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // disable response buffering of nginx
+	w.WriteHeader(status)
+
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	flush()
+
+	var heartbeat <-chan time.Time
+	if HeartbeatInterval > 0 {
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	var buf bytes.Buffer
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-heartbeat:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+			flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			buf.Reset()
+			if err := encodeEvent(&buf, e); err != nil {
+				return
+			}
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return
+			}
+			flush()
+		}
+	}
+}
+
+var (
+	eventFieldReplacer = strings.NewReplacer("\r", "", "\n", "")
+	eventLineReplacer  = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+)
+
+func encodeEvent(buf *bytes.Buffer, e Event) error {
+	if e.ID != "" {
+		buf.WriteString("id: ")
+		buf.WriteString(eventFieldReplacer.Replace(e.ID))
+		buf.WriteByte('\n')
+	}
+	if e.Event != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(eventFieldReplacer.Replace(e.Event))
+		buf.WriteByte('\n')
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: ")
+		buf.WriteString(strconv.FormatInt(e.Retry.Milliseconds(), 10))
+		buf.WriteByte('\n')
+	}
+
+	var data []byte
+	switch d := e.Data.(type) {
+	case nil:
+	case string:
+		data = []byte(d)
+	case []byte:
+		data = d
+	default:
+		var err error
+		if data, err = json.Marshal(d); err != nil {
+			return err
+		}
+	}
+	// every line of the data needs its own field, and CRLF, a lone CR and LF
+	// all end a line
+	for _, line := range strings.Split(eventLineReplacer.Replace(string(data)), "\n") {
+		buf.WriteString("data: ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,90 @@
+package binding_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+)
+
+func TestHandlerFuncEvents(t *testing.T) {
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/", binding.HandlerFunc(func(id binding.LastEventID) <-chan binding.Event {
+		ch := make(chan binding.Event, 5)
+		ch <- binding.Event{ID: "2", Event: "resumed", Data: string(id), Retry: 3 * time.Second}
+		ch <- binding.Event{Data: "line 1\nline 2"}
+		ch <- binding.Event{Data: "line 1\r\nline 2\r"}
+		ch <- binding.Event{Data: "hi\revent: admin\rid: 9"}
+		ch <- binding.Event{ID: "3", Data: Person{Name: "John"}}
+		close(ch)
+		return ch
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "id: 2\nevent: resumed\nretry: 3000\ndata: 1\n\n"+
+		"data: line 1\ndata: line 2\n\n"+
+		"data: line 1\ndata: line 2\ndata: \n\n"+
+		"data: hi\ndata: event: admin\ndata: id: 9\n\n"+
+		"id: 3\ndata: {\"name\":\"John\"}\n\n", w.Body.String())
+}
+
+// heartbeatRecorder closes heartbeats when the first heartbeat is written.
+type heartbeatRecorder struct {
+	*httptest.ResponseRecorder
+	heartbeats chan struct{}
+	once       sync.Once
+}
+
+func (w *heartbeatRecorder) Write(p []byte) (int, error) {
+	n, err := w.ResponseRecorder.Write(p)
+	if strings.HasPrefix(string(p), ": heartbeat") {
+		w.once.Do(func() { close(w.heartbeats) })
+	}
+	return n, err
+}
+
+func TestHandlerFuncEventsHeartbeat(t *testing.T) {
+	defer func(d time.Duration) {
+		binding.HeartbeatInterval = d
+	}(binding.HeartbeatInterval)
+	binding.HeartbeatInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &heartbeatRecorder{ResponseRecorder: httptest.NewRecorder(), heartbeats: make(chan struct{})}
+
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/", binding.HandlerFunc(func() <-chan binding.Event {
+		go func() {
+			select {
+			case <-w.heartbeats:
+			case <-time.After(10 * time.Second):
+			}
+			cancel() // client disconnects
+		}()
+		return make(chan binding.Event) // never closed
+	}))
+
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	assert.Contains(t, w.Body.String(), ": heartbeat\n\n")
+}
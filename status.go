@@ -8,11 +8,15 @@ import (
 
 	"github.com/go-playground/form/v4"
 	"github.com/go-playground/validator/v10"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 // NewBindingError returns an error indicating the request is invalid and cannot be bound to an object.
+// The metav1.StatusDetails name the group and kind of obj, see GroupKinder and RegisterGroupKind,
+// and its name, so obj should be the object that was bound rather than an empty model.
 func NewBindingError(err error, obj interface{}) *apierrors.StatusError {
 	if err == nil {
 		return &apierrors.StatusError{metav1.Status{
@@ -21,22 +25,25 @@ func NewBindingError(err error, obj interface{}) *apierrors.StatusError {
 		}}
 	}
 
+	gk := qualifiedKind(obj)
+	name := nameOf(obj)
+
 	switch t := err.(type) {
 	case *validator.InvalidValidationError:
 		return &apierrors.StatusError{metav1.Status{
 			Status: metav1.StatusFailure,
 			Code:   http.StatusUnprocessableEntity,
 			Reason: metav1.StatusReasonInvalid,
-			//Details: &metav1.StatusDetails{
-			//	Group:  qualifiedKind.Group,
-			//	Kind:   qualifiedKind.Kind,
-			//	Name:   name,
-			//	Causes: causes,
-			//},
+			Details: &metav1.StatusDetails{
+				Group: gk.Group,
+				Kind:  gk.Kind,
+				Name:  name,
+			},
 			Message: err.Error(),
 		}}
 	case validator.ValidationErrors:
 		causes := make([]metav1.StatusCause, 0, len(t))
+		errs := make([]error, 0, len(t))
 		for i := range t {
 			err := t[i]
 			st := metav1.CauseTypeFieldValueInvalid
@@ -48,19 +55,19 @@ func NewBindingError(err error, obj interface{}) *apierrors.StatusError {
 				Message: err.Error(),
 				Field:   err.Namespace(),
 			})
+			errs = append(errs, err)
 		}
 		return &apierrors.StatusError{metav1.Status{
 			Status: metav1.StatusFailure,
 			Code:   http.StatusUnprocessableEntity,
 			Reason: metav1.StatusReasonInvalid,
 			Details: &metav1.StatusDetails{
-				// Group:  qualifiedKind.Group,
-				// Kind:   qualifiedKind.Kind,
-				// Name:   name,
+				Group:  gk.Group,
+				Kind:   gk.Kind,
+				Name:   name,
 				Causes: causes,
 			},
-			// Message: fmt.Sprintf("%s %q is invalid: %v", qualifiedKind.String(), name, errs.ToAggregate()),
-			Message: fmt.Sprintf("%s is invalid", reflect.TypeOf(obj)),
+			Message: fmt.Sprintf("%s %q is invalid: %v", gk.String(), name, utilerrors.NewAggregate(errs)),
 		}}
 	case form.DecodeErrors:
 		ot := reflect.TypeOf(obj)
@@ -80,16 +87,23 @@ func NewBindingError(err error, obj interface{}) *apierrors.StatusError {
 			Code:   http.StatusBadRequest,
 			Reason: metav1.StatusReasonBadRequest,
 			Details: &metav1.StatusDetails{
-				// Group:  qualifiedKind.Group,
-				// Kind:   qualifiedKind.Kind,
-				// Name:   name,
+				Group:  gk.Group,
+				Kind:   gk.Kind,
+				Name:   name,
 				Causes: causes,
 			},
 			Message: fmt.Sprintf("failed to decode into %s", reflect.TypeOf(obj)),
 		}}
+	case *schemaError:
+		return newSchemaError(t.ValidationError, obj, t)
+	case *jsonschema.ValidationError:
+		return newSchemaError(t, obj, nil)
 	case *form.InvalidDecoderError, *gojson.InvalidUnmarshalError:
 		return apierrors.NewInternalError(err) // error due to bug in source code
 	default:
+		if status, ok := mapError(err).(*apierrors.StatusError); ok {
+			return status
+		}
 		return apierrors.NewBadRequest(err.Error()) // error due to bad input from request body
 	}
 }
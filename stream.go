@@ -0,0 +1,163 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+)
+
+// ndjsonContentType is written when the client accepts newline delimited JSON.
+const ndjsonContentType = "application/x-ndjson"
+
+// isStream reports whether values of t are streamed element by element:
+// a receivable channel <-chan T or an iterator func(yield func(T) bool).
+func isStream(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan:
+		return t.ChanDir()&reflect.RecvDir != 0
+	case reflect.Func:
+		if t.NumIn() != 1 || t.NumOut() != 0 || t.IsVariadic() {
+			return false
+		}
+		yield := t.In(0)
+		return yield.Kind() == reflect.Func && yield.NumIn() == 1 && yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool
+	}
+	return false
+}
+
+// acceptsNDJSON reports whether the client asked for newline delimited JSON,
+// with a quality that is not 0 and at least that of application/json.
+func acceptsNDJSON(req *http.Request) bool {
+	var ndjsonQ, jsonQ float64
+	for _, r := range parseAccept(req.Header.Get("Accept")) {
+		switch r.mediaType {
+		case ndjsonContentType, "application/jsonl":
+			ndjsonQ = math.Max(ndjsonQ, r.q)
+		case "application/json":
+			jsonQ = math.Max(jsonQ, r.q)
+		}
+	}
+	return ndjsonQ > 0 && ndjsonQ >= jsonQ
+}
+
+// writeStream writes the elements of a channel or an iterator as they are
+// produced, either as newline delimited JSON or as a JSON array, flushing after
+// each element. It stops early when the request context is done or the client
+// can not be written to anymore. If an element can not be encoded, the JSON
+// array is left unterminated, so that the client does not mistake the elements
+// written so far for all of them.
+func writeStream(w http.ResponseWriter, req *http.Request, status int, v reflect.Value) {
+	ctx := req.Context()
+	ndjson := acceptsNDJSON(req)
+	if ndjson {
+		w.Header().Set("Content-Type", ndjsonContentType)
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	flusher, _ := w.(http.Flusher)
+
+	var buf bytes.Buffer
+	n := 0
+	failed := false
+	write := func(elem reflect.Value) bool {
+		data, err := json.Marshal(elem.Interface())
+		if err != nil {
+			failed = true
+			return false
+		}
+
+		buf.Reset()
+		if !ndjson {
+			if n == 0 {
+				buf.WriteByte('[')
+			} else {
+				buf.WriteByte(',')
+			}
+		}
+		buf.Write(data)
+		if ndjson {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return false
+		}
+		n++
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return ctx.Err() == nil
+	}
+
+	switch v.Kind() {
+	case reflect.Chan:
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			{Dir: reflect.SelectRecv, Chan: v},
+		}
+		for {
+			chosen, elem, ok := reflect.Select(cases)
+			if chosen == 0 || !ok || !write(elem) {
+				break
+			}
+		}
+	case reflect.Func:
+		yieldType := v.Type().In(0)
+		yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(ctx.Err() == nil && write(args[0])).Convert(yieldType.Out(0))}
+		})
+		v.Call([]reflect.Value{yield})
+	}
+
+	if !ndjson && !failed && ctx.Err() == nil {
+		if n == 0 {
+			_, _ = w.Write([]byte("[]"))
+		} else {
+			_, _ = w.Write([]byte("]"))
+		}
+	}
+}
+
+// writeReader copies r to the response. Unless a Content-Type header is already
+// set, it is detected from the first bytes of r. r is closed afterwards if it is
+// an io.Closer.
+func writeReader(w http.ResponseWriter, req *http.Request, status int, r io.Reader) error {
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		var sniff [512]byte
+		n, err := io.ReadFull(r, sniff[:])
+		switch err {
+		case nil:
+			r = io.MultiReader(bytes.NewReader(sniff[:n]), r)
+		case io.EOF, io.ErrUnexpectedEOF:
+			r = bytes.NewReader(sniff[:n])
+		default:
+			return err
+		}
+		w.Header().Set("Content-Type", http.DetectContentType(sniff[:n]))
+	}
+
+	w.WriteHeader(status)
+	_, _ = io.Copy(w, contextReader{ctx: req.Context(), r: r})
+	return nil
+}
+
+// contextReader stops reading once ctx is done, so that copying to a client
+// which went away does not drain the whole reader.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,187 @@
+package binding_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+)
+
+func people(names ...string) <-chan Person {
+	ch := make(chan Person)
+	go func() {
+		defer close(ch)
+		for _, name := range names {
+			ch <- Person{Name: name}
+		}
+	}()
+	return ch
+}
+
+func peopleSeq(names ...string) func(yield func(Person) bool) {
+	return func(yield func(Person) bool) {
+		for _, name := range names {
+			if !yield(Person{Name: name}) {
+				return
+			}
+		}
+	}
+}
+
+func TestHandlerFuncStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     interface{}
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "channel as JSON array",
+			handler:     func() <-chan Person { return people("John", "Jane") },
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name":"John"},{"name":"Jane"}]`,
+		},
+		{
+			name:        "empty channel",
+			handler:     func() (<-chan Person, error) { return people(), nil },
+			contentType: "application/json; charset=utf-8",
+			body:        `[]`,
+		},
+		{
+			name:        "channel as NDJSON",
+			handler:     func() <-chan Person { return people("John", "Jane") },
+			accept:      "application/x-ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"name\":\"John\"}\n{\"name\":\"Jane\"}\n",
+		},
+		{
+			name:        "NDJSON not acceptable",
+			handler:     func() <-chan Person { return people("John") },
+			accept:      "application/x-ndjson;q=0, application/json",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name":"John"}]`,
+		},
+		{
+			name:        "NDJSON preferred less than JSON",
+			handler:     func() <-chan Person { return people("John") },
+			accept:      "application/x-ndjson;q=0.5, application/json",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name":"John"}]`,
+		},
+		{
+			name: "unencodable element leaves the array open",
+			handler: func() func(func(interface{}) bool) {
+				return func(yield func(interface{}) bool) {
+					_ = yield(Person{Name: "John"}) && yield(func() {}) && yield(Person{Name: "Jane"})
+				}
+			},
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name":"John"}`,
+		},
+		{
+			name:        "iterator as JSON array",
+			handler:     func() func(func(Person) bool) { return peopleSeq("John", "Jane") },
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name":"John"},{"name":"Jane"}]`,
+		},
+		{
+			name: "iterator as NDJSON",
+			handler: func() (binding.Response[func(func(Person) bool)], error) {
+				return binding.Response[func(func(Person) bool)]{Status: http.StatusAccepted, Body: peopleSeq("John")}, nil
+			},
+			accept:      "application/x-ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"name\":\"John\"}\n",
+		},
+		{
+			name:        "reader with detected Content-Type",
+			handler:     func() io.Reader { return strings.NewReader("<html><body>handler</body></html>") },
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><body>handler</body></html>",
+		},
+		{
+			name: "reader with Content-Type",
+			handler: func() (binding.Response[io.Reader], error) {
+				return binding.Response[io.Reader]{
+					Header: http.Header{"Content-Type": {"text/csv"}},
+					Body:   strings.NewReader("name\nJohn\n"),
+				}, nil
+			},
+			contentType: "text/csv",
+			body:        "name\nJohn\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.Get("/", binding.HandlerFunc(tt.handler))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestHandlerFuncStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var yielded int
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.Get("/chan", binding.HandlerFunc(func() <-chan Person {
+		ch := make(chan Person)
+		go func() {
+			ch <- Person{Name: "John"}
+			cancel()
+		}()
+		return ch // never closed
+	}))
+	m.Get("/seq", binding.HandlerFunc(func() func(func(Person) bool) {
+		return func(yield func(Person) bool) {
+			for yield(Person{Name: "John"}) {
+				yielded++
+				if yielded == 3 {
+					cancel()
+				}
+			}
+		}
+	}))
+
+	for _, url := range []string{"/chan", "/seq"} {
+		t.Run(url, func(t *testing.T) {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				req := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+				m.ServeHTTP(httptest.NewRecorder(), req)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("stream did not stop after the request context was canceled")
+			}
+		})
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	cancel()
+	assert.Equal(t, 3, yielded)
+}
@@ -0,0 +1,92 @@
+package binding
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TableConvertor is implemented by values returned from a HandlerFunc, usually
+// lists, that can be written as a metav1.Table. A Table is written instead of
+// the value itself if the Accept header of the request asks for one, like
+// kubectl does with
+//
+//	Accept: application/json;as=Table;g=meta.k8s.io;v=v1
+//
+// opts holds the includeObject query parameter of the request.
+type TableConvertor interface {
+	ConvertToTable(ctx context.Context, opts *metav1.TableOptions) (*metav1.Table, error)
+}
+
+type tableConvertorFunc func(ctx context.Context, opts *metav1.TableOptions) (*metav1.Table, error)
+
+var tableConvertors = map[reflect.Type]func(v interface{}) tableConvertorFunc{}
+
+// RegisterTableConvertor converts values of type T to metav1.Table with fn, for
+// types that can not implement TableConvertor themselves, for example
+//
+//	binding.RegisterTableConvertor(func(ctx context.Context, list []Person, opts *metav1.TableOptions) (*metav1.Table, error) {
+//		table := &metav1.Table{
+//			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}},
+//		}
+//		for _, p := range list {
+//			table.Rows = append(table.Rows, metav1.TableRow{Cells: []interface{}{p.Name}})
+//		}
+//		return table, nil
+//	})
+//
+// A nil fn removes the convertor of T. RegisterTableConvertor is not safe to
+// call while serving requests.
+func RegisterTableConvertor[T any](fn func(ctx context.Context, v T, opts *metav1.TableOptions) (*metav1.Table, error)) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if fn == nil {
+		delete(tableConvertors, typ)
+		return
+	}
+	tableConvertors[typ] = func(v interface{}) tableConvertorFunc {
+		return func(ctx context.Context, opts *metav1.TableOptions) (*metav1.Table, error) {
+			return fn(ctx, v.(T), opts)
+		}
+	}
+}
+
+// tableConvertorOf returns how to convert v to metav1.Table, or nil if v can not
+// be converted.
+func tableConvertorOf(v interface{}) tableConvertorFunc {
+	if isNil(v) {
+		return nil
+	}
+	if c, ok := v.(TableConvertor); ok {
+		return c.ConvertToTable
+	}
+	if convertor, ok := tableConvertors[reflect.TypeOf(v)]; ok {
+		return convertor(v)
+	}
+	return nil
+}
+
+func convertToTable(req *http.Request, convert tableConvertorFunc) (*metav1.Table, error) {
+	opts := &metav1.TableOptions{
+		IncludeObject: metav1.IncludeObjectPolicy(req.URL.Query().Get("includeObject")),
+	}
+	table, err := convert(req.Context(), opts)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		table = &metav1.Table{}
+	}
+	if table.APIVersion == "" && table.Kind == "" {
+		table.APIVersion = metav1.SchemeGroupVersion.String()
+		table.Kind = "Table"
+	}
+	if table.ColumnDefinitions == nil {
+		table.ColumnDefinitions = []metav1.TableColumnDefinition{}
+	}
+	if table.Rows == nil {
+		table.Rows = []metav1.TableRow{}
+	}
+	return table, nil
+}
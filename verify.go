@@ -0,0 +1,129 @@
+package binding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// injectingHandler is the http.Handler returned by the middleware of this
+// package. Besides serving the request, it reports the types the middleware
+// injects, so that Verify can resolve HandlerFunc parameters before serving,
+// the model bound by the Bind family, so that OpenAPI can describe it, and the
+// constructor registered by Provide, so that Verify can resolve its parameters.
+type injectingHandler struct {
+	http.HandlerFunc
+	types    []reflect.Type
+	model    *bindingPlan
+	provider *provider
+}
+
+func injecting(types []reflect.Type, h http.HandlerFunc) http.Handler {
+	return &injectingHandler{HandlerFunc: h, types: types}
+}
+
+type planKey struct{}
+
+// handlerFuncPC identifies the http.HandlerFunc returned by HandlerFunc.
+// All of them share the same code pointer, so it is safe to ask them for
+// their plan without invoking any user code.
+var handlerFuncPC = reflect.ValueOf(HandlerFunc(func() {})).Pointer()
+
+// Verify walks the routes of r and checks that every parameter of a HandlerFunc
+// is provided by the Injector, Map, MapTo, Set, Provide or Bind family
+// middleware on the route, as are the parameters of the providers it needs,
+// transitively. It reports all unresolved dependencies, so that they are found
+// before the server starts instead of panicking at request time.
+//
+// Values injected from Inject can not be known without serving a request. Pass
+// their types as known to consider them provided on every route.
+func Verify(r chi.Routes, known ...reflect.Type) error {
+	var errs []error
+	err := chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		plan := planOf(handler)
+		if plan == nil {
+			return nil // not a HandlerFunc
+		}
+
+		provided := append([]reflect.Type{lastEventIDType}, known...)
+		var providers []*provider
+		for _, mw := range middlewares {
+			if h, ok := mw(handler).(*injectingHandler); ok {
+				if h.provider != nil {
+					providers = append(providers, h.provider)
+				} else {
+					provided = append(provided, h.types...)
+				}
+			}
+		}
+		if !resolvable(injectorTypes[0], provided) {
+			errs = append(errs, fmt.Errorf("%s %s: register Injector middleware", method, route))
+			return nil
+		}
+
+		checked := map[*provider]bool{}
+		var check func(fnType reflect.Type, in []reflect.Type)
+		check = func(fnType reflect.Type, in []reflect.Type) {
+			for _, t := range in {
+				if resolvable(t, provided) {
+					continue
+				}
+				p := providerOf(t, providers)
+				if p == nil {
+					errs = append(errs, fmt.Errorf("%s %s: fn %s has unresolved dependency %v", method, route, fnType, t))
+				} else if !checked[p] {
+					checked[p] = true
+					check(p.fnType, p.in)
+				}
+			}
+		}
+		check(plan.typ, plan.in)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// planOf returns the plan of a handler created by HandlerFunc or nil for any
+// other handler.
+func planOf(handler http.Handler) *handlerPlan {
+	fn, ok := handler.(http.HandlerFunc)
+	if !ok || reflect.ValueOf(fn).Pointer() != handlerFuncPC {
+		return nil
+	}
+
+	var plan *handlerPlan
+	ctx := context.WithValue(context.Background(), planKey{}, &plan)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	fn(nil, req)
+	return plan
+}
+
+// providerOf returns the provider of t among the providers of a route, like
+// scope.provider does: the innermost one of type t or implementing it.
+func providerOf(t reflect.Type, providers []*provider) *provider {
+	for i := len(providers) - 1; i >= 0; i-- {
+		p := providers[i]
+		if p.typ == t || (t.Kind() == reflect.Interface && p.typ.Implements(t)) {
+			return p
+		}
+	}
+	return nil
+}
+
+// resolvable mirrors how the injector looks up values: by exact type or, for
+// interfaces, by any mapped type implementing it.
+func resolvable(t reflect.Type, provided []reflect.Type) bool {
+	for _, p := range provided {
+		if p == t || (t.Kind() == reflect.Interface && p.Implements(t)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package binding_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.wandrs.dev/binding"
+	httpw "go.wandrs.dev/http"
+	"go.wandrs.dev/inject"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+)
+
+type clock interface {
+	Now() string
+}
+
+type fixedClock string
+
+func (c fixedClock) Now() string {
+	return string(c)
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("resolved", func(t *testing.T) {
+		m := chi.NewRouter()
+		m.Use(binding.Injector(render.New()))
+		m.Use(binding.MapTo(fixedClock("now"), (*clock)(nil)))
+		m.With(binding.JSON(Post{}, (*modeler)(nil))).
+			Post("/posts", binding.HandlerFunc(func(w httpw.ResponseWriter, req *http.Request, c clock, p Post, iface modeler) {}))
+		m.With(binding.Map(Person{})).
+			Get("/people", binding.HandlerFunc(func(p Person) Person { return p }))
+		m.Route("/groups", func(r chi.Router) {
+			r.With(binding.Bind(Group{})).
+				Post("/", binding.HandlerFunc(func(g Group, c clock) (Group, error) { return g, nil }))
+		})
+		m.Get("/plain", func(w http.ResponseWriter, req *http.Request) {})
+
+		assert.NoError(t, binding.Verify(m))
+	})
+
+	t.Run("unresolved", func(t *testing.T) {
+		m := chi.NewRouter()
+		m.Use(binding.Injector(render.New()))
+		m.With(binding.Form(Post{})).
+			Post("/posts", binding.HandlerFunc(func(p Post, person Person, c clock) {}))
+		m.Get("/people", binding.HandlerFunc(func(p Person) Person { return p }))
+
+		err := binding.Verify(m)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "POST /posts: fn func(binding_test.Post, binding_test.Person, binding_test.clock) has unresolved dependency binding_test.Person")
+			assert.Contains(t, err.Error(), "has unresolved dependency binding_test.clock")
+			assert.Contains(t, err.Error(), "GET /people: fn func(binding_test.Person) binding_test.Person has unresolved dependency binding_test.Person")
+			assert.NotContains(t, err.Error(), "dependency binding_test.Post")
+		}
+	})
+
+	t.Run("known types from Inject", func(t *testing.T) {
+		m := chi.NewRouter()
+		m.Use(binding.Injector(render.New()))
+		m.Use(binding.Inject(func(injector inject.Injector) error {
+			injector.MapTo(fixedClock("now"), (*clock)(nil))
+			return nil
+		}))
+		m.Get("/now", binding.HandlerFunc(func(c clock) string { return c.Now() }))
+
+		assert.Error(t, binding.Verify(m))
+		assert.NoError(t, binding.Verify(m, reflect.TypeOf((*clock)(nil)).Elem()))
+	})
+
+	t.Run("missing Injector", func(t *testing.T) {
+		m := chi.NewRouter()
+		m.Get("/", binding.HandlerFunc(func(w http.ResponseWriter) {}))
+
+		err := binding.Verify(m)
+		if assert.Error(t, err) {
+			assert.True(t, strings.HasPrefix(err.Error(), "GET /: register Injector middleware"))
+		}
+	})
+}
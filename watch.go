@@ -0,0 +1,109 @@
+package binding
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.wandrs.dev/inject"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+var watchEventType = reflect.TypeOf(watch.Event{})
+
+// isWatchChan reports whether t is a receivable channel of watch.Event.
+func isWatchChan(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.ChanDir()&reflect.RecvDir != 0 && t.Elem() == watchEventType
+}
+
+// watchOptions returns whether bookmarks were asked for and how long the watch
+// may last, from the bound ListOptions or else the query parameters.
+func watchOptions(req *http.Request, injector inject.Injector) (bookmarks bool, timeout time.Duration) {
+	var opts metav1.ListOptions
+	if v := injector.GetVal(listOptionsType); v.IsValid() {
+		opts = v.Interface().(metav1.ListOptions)
+	} else if req.URL != nil {
+		query := req.URL.Query()
+		opts.AllowWatchBookmarks, _ = strconv.ParseBool(query.Get("allowWatchBookmarks"))
+		if seconds, err := strconv.ParseInt(query.Get("timeoutSeconds"), 10, 64); err == nil {
+			opts.TimeoutSeconds = &seconds
+		}
+	}
+	if opts.TimeoutSeconds != nil && *opts.TimeoutSeconds > 0 {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	return opts.AllowWatchBookmarks, timeout
+}
+
+// writeWatch writes the events of ch as a chunked stream of metav1.WatchEvent
+// frames, like kube-apiserver does for ?watch=true. Bookmark events are only
+// written if the client set allowWatchBookmarks. The stream ends when ch is
+// closed, timeoutSeconds have passed or the client goes away, and stop is
+// called afterwards if it is not nil.
+func writeWatch(w http.ResponseWriter, req *http.Request, injector inject.Injector, status int, ch <-chan watch.Event, stop func()) {
+	if stop != nil {
+		defer stop()
+	}
+	bookmarks, timeout := watchOptions(req, injector)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	w.Header().Set("Content-Type", jsonMediaType)
+	w.WriteHeader(status)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	ctx := req.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-expired:
+			return
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if event.Type == watch.Bookmark && !bookmarks {
+				continue
+			}
+			data, err := encodeWatchEvent(event)
+			if err != nil {
+				return
+			}
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// encodeWatchEvent encodes event as a metav1.WatchEvent followed by a newline.
+func encodeWatchEvent(event watch.Event) ([]byte, error) {
+	obj, err := json.Marshal(event.Object)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(metav1.WatchEvent{
+		Type:   string(event.Type),
+		Object: runtime.RawExtension{Raw: obj},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,116 @@
+package binding_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.wandrs.dev/binding"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func object(name string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestHandlerFuncWatch(t *testing.T) {
+	events := func() watch.Interface {
+		w := watch.NewFakeWithChanSize(3, false)
+		w.Add(object("john"))
+		w.Action(watch.Bookmark, object(""))
+		w.Delete(object("john"))
+		w.Stop()
+		return w
+	}
+
+	tests := []struct {
+		name    string
+		handler interface{}
+		query   string
+		body    string
+	}{
+		{
+			name:    "watch.Interface",
+			handler: func() watch.Interface { return events() },
+			body: `{"type":"ADDED","object":{"metadata":{"name":"john","creationTimestamp":null}}}` + "\n" +
+				`{"type":"DELETED","object":{"metadata":{"name":"john","creationTimestamp":null}}}` + "\n",
+		},
+		{
+			name:    "bookmarks",
+			handler: func() (watch.Interface, error) { return events(), nil },
+			query:   "?watch=true&allowWatchBookmarks=true",
+			body: `{"type":"ADDED","object":{"metadata":{"name":"john","creationTimestamp":null}}}` + "\n" +
+				`{"type":"BOOKMARK","object":{"metadata":{"creationTimestamp":null}}}` + "\n" +
+				`{"type":"DELETED","object":{"metadata":{"name":"john","creationTimestamp":null}}}` + "\n",
+		},
+		{
+			name: "channel",
+			handler: func() <-chan watch.Event {
+				ch := make(chan watch.Event, 1)
+				ch <- watch.Event{Type: watch.Modified, Object: object("jane")}
+				close(ch)
+				return ch
+			},
+			body: `{"type":"MODIFIED","object":{"metadata":{"name":"jane","creationTimestamp":null}}}` + "\n",
+		},
+		{
+			name: "timeout",
+			handler: func() <-chan watch.Event {
+				return make(chan watch.Event) // never closed
+			},
+			query: "?watch=true&timeoutSeconds=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := chi.NewRouter()
+			m.Use(binding.Injector(render.New()))
+			m.Get("/", binding.HandlerFunc(tt.handler))
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestHandlerFuncWatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fake := watch.NewFake()
+	m := chi.NewRouter()
+	m.Use(binding.Injector(render.New()))
+	m.With(binding.ListOptions()).Get("/", binding.HandlerFunc(func() watch.Interface {
+		go func() {
+			fake.Add(object("john"))
+			cancel()
+		}()
+		return fake
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/?watch=true", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.ServeHTTP(w, req)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not stop after the client went away")
+	}
+	assert.True(t, fake.IsStopped())
+}